@@ -11,6 +11,7 @@ import (
 
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 var verbose = flag.Bool("verbose", false, "add more parameters to the output")
+var structure = flag.String("structure", string(bitvector.Interleaved), "rank select structure: interleaved, interleaved-compact, rank9, poppy, rrr or elias-fano")
 
 func main() {
 
@@ -47,7 +48,7 @@ func main() {
 	defer outputFile.Close()
 
 	// here the actual processing begins
-	err = bitvector.ProcessFile(inputFile, outputFile, os.Stdout, *verbose)
+	err = bitvector.ProcessFileWithStructure(inputFile, outputFile, os.Stdout, *verbose, bitvector.Structure(*structure))
 	if err != nil {
 		log.Fatal("error processing file:", err)
 	}
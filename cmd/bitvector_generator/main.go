@@ -35,6 +35,10 @@ func main() {
 				},
 				Value: 10,
 			},
+			&cli.BoolFlag{
+				Name:  "range-queries",
+				Usage: "also generate rank_range and select_from commands",
+			},
 		},
 		Action: func(ctx *cli.Context) error {
 
@@ -52,7 +56,12 @@ func main() {
 			}
 			defer fCommands.Close()
 
-			err = bitvector.GenerateRandomTestCase(ctx.Uint64("vector-length"), ctx.Uint64("commands"), fCommands, fExpected)
+			generated := bitvector.GeneratedCommands
+			if ctx.Bool("range-queries") {
+				generated = bitvector.Commands
+			}
+
+			err = bitvector.GenerateRandomTestCase(ctx.Uint64("vector-length"), ctx.Uint64("commands"), generated, fCommands, fExpected)
 			if err != nil {
 				return err
 			}
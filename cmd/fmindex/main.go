@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/fmindex"
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+
+	indexFlag := &cli.PathFlag{
+		Name: "index",
+		Aliases: []string{
+			"i",
+		},
+		Required: true,
+	}
+
+	app := cli.App{
+		Name:  "fmindex",
+		Usage: "build an FM-index of a file and query it",
+		Commands: []*cli.Command{
+			{
+				Name:      "build",
+				Usage:     "index the text file",
+				ArgsUsage: "[text]",
+				Flags: []cli.Flag{
+					indexFlag,
+					&cli.Uint64Flag{
+						Name: "sample-rate",
+						Aliases: []string{
+							"s",
+						},
+						Value: fmindex.DefaultSampleRate,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 1 {
+						return fmt.Errorf("need exactly one text file")
+					}
+
+					text, err := os.ReadFile(ctx.Args().First())
+					if err != nil {
+						return err
+					}
+
+					rate := ctx.Uint64("sample-rate")
+					if rate == 0 {
+						return fmt.Errorf("sample rate has to be positive")
+					}
+					idx := fmindex.NewWithSampleRate(text, rate)
+
+					f, err := os.Create(ctx.Path("index"))
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+
+					if _, err := idx.WriteTo(f); err != nil {
+						return err
+					}
+
+					log.Printf("indexed %d bytes into %d bits\n", idx.Len(), idx.Size())
+					return f.Close()
+				},
+			},
+			{
+				Name:      "count",
+				Usage:     "number of occurrences of the pattern",
+				ArgsUsage: "[pattern]",
+				Flags:     []cli.Flag{indexFlag},
+				Action: func(ctx *cli.Context) error {
+					idx, pattern, err := loadWithPattern(ctx)
+					if err != nil {
+						return err
+					}
+
+					fmt.Println(idx.Count(pattern))
+					return nil
+				},
+			},
+			{
+				Name:      "locate",
+				Usage:     "start positions of all occurrences of the pattern, one per line",
+				ArgsUsage: "[pattern]",
+				Flags:     []cli.Flag{indexFlag},
+				Action: func(ctx *cli.Context) error {
+					idx, pattern, err := loadWithPattern(ctx)
+					if err != nil {
+						return err
+					}
+
+					out := bufio.NewWriter(os.Stdout)
+					defer out.Flush()
+					for _, position := range idx.Locate(pattern) {
+						fmt.Fprintln(out, position)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "extract",
+				Usage:     "text between the positions [from, to)",
+				ArgsUsage: "[from] [to]",
+				Flags:     []cli.Flag{indexFlag},
+				Action: func(ctx *cli.Context) error {
+					if ctx.NArg() != 2 {
+						return fmt.Errorf("need a from and a to position")
+					}
+
+					from, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+					if err != nil {
+						return fmt.Errorf("from position not a valid number: %w", err)
+					}
+					to, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+					if err != nil {
+						return fmt.Errorf("to position not a valid number: %w", err)
+					}
+
+					idx, err := load(ctx.Path("index"))
+					if err != nil {
+						return err
+					}
+					if from > to || to > idx.Len() {
+						return fmt.Errorf("range [%d, %d) outside of the text of length %d", from, to, idx.Len())
+					}
+
+					_, err = os.Stdout.Write(idx.Extract(from, to))
+					return err
+				},
+			},
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func load(path string) (*fmindex.Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var idx fmindex.Index
+	if _, err := idx.ReadFrom(bufio.NewReader(f)); err != nil {
+		return nil, fmt.Errorf("could not read index: %w", err)
+	}
+	return &idx, nil
+}
+
+func loadWithPattern(ctx *cli.Context) (*fmindex.Index, []byte, error) {
+	if ctx.NArg() != 1 {
+		return nil, nil, fmt.Errorf("need exactly one pattern")
+	}
+
+	idx, err := load(ctx.Path("index"))
+	return idx, []byte(ctx.Args().First()), err
+}
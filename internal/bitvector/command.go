@@ -10,19 +10,41 @@ import (
 
 type Command string
 
+// Commands of the command file, one per line with space separated arguments.
+// alpha is 0 or 1, positions start at 0 and occurrences at 1.
 const (
+	// access i: bit at position i
 	Access Command = "access"
-	Rank   Command = "rank"
+	// rank alpha i: number of alphas before position i
+	Rank Command = "rank"
+	// select alpha n: position of the n'th alpha
 	Select Command = "select"
+	// rank_range alpha l r: number of alphas in [l, r)
+	RankRange Command = "rank_range"
+	// select_from alpha p k: position of the k'th alpha at or after position p
+	SelectFrom Command = "select_from"
 )
 
 var Commands []Command = []Command{
+	Access, Rank, Select, RankRange, SelectFrom,
+}
+
+// Commands of generated test cases by default, the original benchmark mix
+var GeneratedCommands []Command = []Command{
 	Access, Rank, Select,
 }
 
 type CommandFuncGenerator func(args []string) (CommandFunc, error)
+
+// Executes a parsed command. Invalid queries return errors matching
+// bit.ErrOutOfRange or bit.ErrNotEnoughOccurrences.
 type CommandFunc func(vec bit.RankSelectVector) (uint64, error)
 
+// Whether the error only affects a single query and not the whole run
+func IsQueryError(err error) bool {
+	return errors.Is(err, bit.ErrOutOfRange) || errors.Is(err, bit.ErrNotEnoughOccurrences)
+}
+
 var CommandExecutors = map[Command]CommandFuncGenerator{
 	Access: func(args []string) (CommandFunc, error) {
 		if len(args) != 1 {
@@ -35,7 +57,10 @@ var CommandExecutors = map[Command]CommandFuncGenerator{
 		}
 
 		return func(vec bit.RankSelectVector) (uint64, error) {
-			b := vec.Access(position)
+			b, err := vec.TryAccess(position)
+			if err != nil {
+				return 0, err
+			}
 			v := uint64(0)
 			if b {
 				v = 1
@@ -59,7 +84,7 @@ var CommandExecutors = map[Command]CommandFuncGenerator{
 		}
 
 		return func(vec bit.RankSelectVector) (uint64, error) {
-			return vec.Rank(alpha, position), nil
+			return vec.TryRank(alpha, position)
 		}, nil
 
 	},
@@ -79,7 +104,55 @@ var CommandExecutors = map[Command]CommandFuncGenerator{
 		}
 
 		return func(vec bit.RankSelectVector) (uint64, error) {
-			return vec.Select(alpha, position), nil
+			return vec.TrySelect(alpha, position)
+		}, nil
+	},
+	RankRange: func(args []string) (CommandFunc, error) {
+		if len(args) != 3 {
+			return nil, errors.New("rank_range only accepts three arguments")
+		}
+
+		alpha, err := strconv.ParseBool(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("alpha argument not valid: %w", err)
+		}
+
+		l, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("left position argument not valid: %w", err)
+		}
+
+		r, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("right position argument not valid: %w", err)
+		}
+
+		return func(vec bit.RankSelectVector) (uint64, error) {
+			return vec.TryRankRange(alpha, l, r)
+		}, nil
+	},
+	SelectFrom: func(args []string) (CommandFunc, error) {
+		if len(args) != 3 {
+			return nil, errors.New("select_from only accepts three arguments")
+		}
+
+		alpha, err := strconv.ParseBool(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("alpha argument not valid: %w", err)
+		}
+
+		position, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("position argument not valid: %w", err)
+		}
+
+		k, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("occurrence argument not valid: %w", err)
+		}
+
+		return func(vec bit.RankSelectVector) (uint64, error) {
+			return vec.TrySelectFrom(alpha, position, k)
 		}, nil
 	},
 }
@@ -13,6 +13,16 @@ import (
 )
 
 func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose bool) error {
+	return ProcessFileWithStructure(input, output, statOut, verbose, Interleaved)
+}
+
+// Like ProcessFile, but answers the queries with the given structure
+func ProcessFileWithStructure(input io.Reader, output io.Writer, statOut io.Writer, verbose bool, structure Structure) error {
+
+	builder, ok := StructureBuilders[structure]
+	if !ok {
+		return fmt.Errorf("structure %s not found", structure)
+	}
 
 	var err error
 
@@ -30,8 +40,7 @@ func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose b
 	}
 
 	// init baseVector
-	var baseVector bit.Vector
-	var vecLen uint64
+	var vecBuilder bit.Builder
 	for {
 		b, err := reader.ReadSlice('\n')
 		if err != nil && err != bufio.ErrBufferFull {
@@ -39,24 +48,22 @@ func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose b
 		}
 
 		for _, v := range b {
-			if vecLen%bit.SubvectorBits == 0 {
-				baseVector = append(baseVector, 0)
+			// only digits belong to the vector, not the line ending
+			if v != '0' && v != '1' {
+				continue
 			}
 
-			if v == '1' {
-				baseVector.Set(vecLen)
-			}
-			vecLen++
+			vecBuilder.AppendBit(v == '1')
 		}
 		if err == nil {
 			break
 		}
 	}
+	baseVector := vecBuilder.Vector()
 
-	// Move base vector into new structure, we could also skip this step and directly read into the interleaved structure.
-	// Since this copying is not needed normally it does not contribute to the recorded runtime
+	// Preparing the structure does not contribute to the recorded runtime
 	// The precomputation of our data structure will be done later and will contribute to the runtime
-	vec := bit.NewInterleavedVectorNoPrecompute(baseVector)
+	build := builder(baseVector)
 
 	// To see the implementation of the interleaved vector go to pkg/bit/interleaved_vector.go
 
@@ -88,10 +95,11 @@ func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose b
 	}
 
 	results := make([]uint64, noOfCommands)
+	queryErrors := make([]error, noOfCommands)
 
 	begin := time.Now()
 	// run pre computation which does contribute to the runtime (creating the prev. sums)
-	vec.Precompute()
+	vec := build()
 
 	endPrecompute := time.Now()
 	precomputionTime := endPrecompute.Sub(begin)
@@ -100,7 +108,11 @@ func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose b
 	for i, commandFunc := range commandFuncs {
 
 		result, err := commandFunc(vec)
-		if err != nil {
+		if IsQueryError(err) {
+			// a bad query only invalidates its own result
+			queryErrors[i] = err
+			continue
+		} else if err != nil {
 			return fmt.Errorf("could not run command: %w", err)
 		}
 
@@ -112,17 +124,22 @@ func ProcessFile(input io.Reader, output io.Writer, statOut io.Writer, verbose b
 	runtime := end.Sub(begin)
 	commandTime := end.Sub(endPrecompute)
 
-	for _, v := range results {
+	for i, v := range results {
+		if queryErrors[i] != nil {
+			output.Write([]byte(fmt.Sprintf("error: %s\n", queryErrors[i])))
+			continue
+		}
 		output.Write([]byte(fmt.Sprintf("%d\n", v)))
 	}
 
-	overheadFrac := float64(vec.Overhead()) / float64(vec.Size())
+	// relate the overhead to the logical length, padding counts as overhead
+	overheadFrac := float64(vec.Overhead()) / float64(vec.Bits())
 
 	precomputionFac := float64(precomputionTime) / float64(runtime)
 
 	statOut.Write([]byte(fmt.Sprintf("RESULT name=paul_hegenberg time=%d space=%d", runtime.Milliseconds(), vec.Size())))
 	if verbose {
-		statOut.Write([]byte(fmt.Sprintf(" overhead=%f precompTime=%d precompFac=%f commandTime=%d", overheadFrac, precomputionTime.Milliseconds(), precomputionFac, commandTime.Milliseconds())))
+		statOut.Write([]byte(fmt.Sprintf(" length=%d overhead=%f precompTime=%d precompFac=%f commandTime=%d", vec.Bits(), overheadFrac, precomputionTime.Milliseconds(), precomputionFac, commandTime.Milliseconds())))
 	}
 
 	return nil
@@ -116,6 +116,51 @@ select 1 73
 114
 0
 133
+`,
+		},
+		{
+			desc: "zeros in the padding are not selectable",
+			input: `3
+1011
+select 0 1
+rank 1 4
+access 3
+`,
+			expected: `1
+3
+1
+`,
+		},
+		{
+			desc: "invalid range queries",
+			input: `4
+0110
+rank_range 1 3 2
+rank_range 0 0 5
+select_from 1 2 2
+select_from 1 5 1
+`,
+			expected: `error: out of range: range [3, 2)
+error: out of range: position 5, length 4
+error: not enough occurrences: 2. one at or after position 2 requested
+error: out of range: position 5, length 4
+`,
+		},
+		{
+			desc: "invalid queries do not abort the run",
+			input: `5
+0110
+access 4
+rank 1 5
+select 1 0
+select 1 3
+select 1 2
+`,
+			expected: `error: out of range: position 4, length 4
+error: out of range: position 5, length 4
+error: out of range: occurrences start at 1
+error: not enough occurrences: 3. one requested, only 2 available
+2
 `,
 		},
 	}
@@ -135,3 +180,39 @@ select 1 73
 		})
 	}
 }
+
+func TestFileProcessorStructures(t *testing.T) {
+	input := `9
+0110110100
+access 4
+rank 0 5
+select 1 4
+select 0 4
+rank 1 10
+rank_range 1 2 7
+rank_range 0 3 3
+select_from 1 3 2
+select_from 0 4 1
+`
+	expected := `1
+2
+5
+8
+5
+3
+0
+5
+6
+`
+
+	for _, structure := range bitvector.Structures {
+		t.Run(string(structure), func(t *testing.T) {
+			var output strings.Builder
+			var statOut strings.Builder
+
+			err := bitvector.ProcessFileWithStructure(strings.NewReader(input), &output, &statOut, false, structure)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, output.String())
+		})
+	}
+}
@@ -12,7 +12,8 @@ import (
 	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
 )
 
-func GenerateRandomTestCase(vectorSlices64, commands uint64, commandOut, expectedOut io.Writer) error {
+// Write a random vector and commands drawn uniformly from generated
+func GenerateRandomTestCase(vectorSlices64, commands uint64, generated []Command, commandOut, expectedOut io.Writer) error {
 
 	commandBuffer := bufio.NewWriterSize(commandOut, 1024*1024)
 	defer commandBuffer.Flush()
@@ -25,11 +26,12 @@ func GenerateRandomTestCase(vectorSlices64, commands uint64, commandOut, expecte
 
 	generatorFormatString := "%0" + strconv.FormatUint(bit.SubvectorBits, 10) + "b"
 
-	var vector bit.Vector = make([]bit.Subvector, vectorSlices64)
+	vector := bit.MakeVector(vectorSlices64 * bit.SubvectorBits)
+	subvectors := vector.Subvectors()
 	for i := 0; i < int(vectorSlices64); i++ {
-		vector[i] = bit.Subvector(rand.Uint64())
-		ones += uint64(bits.OnesCount64(uint64(vector[i])))
-		binary := fmt.Sprintf(generatorFormatString, vector[i])
+		subvectors[i] = bit.Subvector(rand.Uint64())
+		ones += uint64(bits.OnesCount64(uint64(subvectors[i])))
+		binary := fmt.Sprintf(generatorFormatString, subvectors[i])
 
 		bBinary := []byte(binary)
 
@@ -49,7 +51,7 @@ func GenerateRandomTestCase(vectorSlices64, commands uint64, commandOut, expecte
 	fullVector := bit.NewInterleavedVector(vector)
 
 	for i := 0; i < int(commands); i++ {
-		fullCommand, expectedResult, err := randomCommandAndResult(fullVector, ones, zeros)
+		fullCommand, expectedResult, err := randomCommandAndResult(fullVector, generated, ones, zeros)
 		if err != nil {
 			return err
 		}
@@ -61,9 +63,9 @@ func GenerateRandomTestCase(vectorSlices64, commands uint64, commandOut, expecte
 	return nil
 }
 
-func randomCommandAndResult(vec bit.RankSelectVector, ones, zeros uint64) (fullCommand string, result string, err error) {
+func randomCommandAndResult(vec bit.RankSelectVector, generated []Command, ones, zeros uint64) (fullCommand string, result string, err error) {
 
-	command, fullCommand := randomCommand(ones, zeros)
+	command, fullCommand := randomCommand(vec, generated, ones, zeros)
 
 	executor, err := CommandExecutors[command](strings.Split(fullCommand, " ")[1:])
 	if err != nil {
@@ -78,16 +80,18 @@ func randomCommandAndResult(vec bit.RankSelectVector, ones, zeros uint64) (fullC
 	return
 }
 
-func randomCommand(ones, zeros uint64) (Command, string) {
+// A random command out of generated. Commands without a valid query for the
+// drawn arguments are skipped and drawn again.
+func randomCommand(vec bit.RankSelectVector, generated []Command, ones, zeros uint64) (Command, string) {
 
-	generators := map[Command]func() string{
-		Access: func() string {
-			return fmt.Sprintf("%s %d", Access, rand.Int63n(int64(ones+zeros)))
+	generators := map[Command]func() (string, bool){
+		Access: func() (string, bool) {
+			return fmt.Sprintf("%s %d", Access, rand.Int63n(int64(ones+zeros))), true
 		},
-		Rank: func() string {
-			return fmt.Sprintf("%s %d %d", Rank, rand.Intn(2), rand.Int63n(int64(ones+zeros)))
+		Rank: func() (string, bool) {
+			return fmt.Sprintf("%s %d %d", Rank, rand.Intn(2), rand.Int63n(int64(ones+zeros))), true
 		},
-		Select: func() string {
+		Select: func() (string, bool) {
 			alpha := rand.Intn(2)
 
 			position := 0
@@ -97,12 +101,38 @@ func randomCommand(ones, zeros uint64) (Command, string) {
 				position = int(rand.Int63n(int64(ones))) + 1
 			}
 
-			return fmt.Sprintf("%s %d %d", Select, alpha, position)
+			return fmt.Sprintf("%s %d %d", Select, alpha, position), true
 		},
-	}
+		RankRange: func() (string, bool) {
+			l := rand.Int63n(int64(ones + zeros))
+			r := l + rand.Int63n(int64(ones+zeros)-l+1)
+			return fmt.Sprintf("%s %d %d %d", RankRange, rand.Intn(2), l, r), true
+		},
+		SelectFrom: func() (string, bool) {
+			alpha := rand.Intn(2) == 1
+
+			// without any alpha every query fails
+			count := vec.Rank(alpha, ones+zeros)
+			if count == 0 {
+				return "", false
+			}
+
+			// only positions with at least one alpha after them
+			position := rand.Uint64() % (vec.Select(alpha, count) + 1)
+			available := count - vec.Rank(alpha, position)
 
-	commandNum := rand.Intn(len(Commands))
-	command := Commands[commandNum]
+			alphaNum := 0
+			if alpha {
+				alphaNum = 1
+			}
+			return fmt.Sprintf("%s %d %d %d", SelectFrom, alphaNum, position, rand.Int63n(int64(available))+1), true
+		},
+	}
 
-	return command, generators[command]()
+	for {
+		command := generated[rand.Intn(len(generated))]
+		if fullCommand, ok := generators[command](); ok {
+			return command, fullCommand
+		}
+	}
 }
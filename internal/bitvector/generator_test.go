@@ -13,6 +13,22 @@ func TestGenerator(t *testing.T) {
 	var commands strings.Builder
 	var expected strings.Builder
 
-	err := bitvector.GenerateRandomTestCase(10, 1000, &commands, &expected)
+	err := bitvector.GenerateRandomTestCase(10, 1000, bitvector.GeneratedCommands, &commands, &expected)
 	assert.NoError(t, err)
+
+	// the default mix stays the original benchmark
+	for _, line := range strings.Split(commands.String(), "\n")[2:] {
+		assert.NotContains(t, line, string(bitvector.RankRange))
+		assert.NotContains(t, line, string(bitvector.SelectFrom))
+	}
+}
+
+func TestGeneratorAllCommands(t *testing.T) {
+
+	var commands strings.Builder
+	var expected strings.Builder
+
+	err := bitvector.GenerateRandomTestCase(10, 1000, bitvector.Commands, &commands, &expected)
+	assert.NoError(t, err)
+	assert.Contains(t, commands.String(), string(bitvector.SelectFrom))
 }
@@ -0,0 +1,60 @@
+package bitvector
+
+import "github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+
+type Structure string
+
+const (
+	Interleaved Structure = "interleaved"
+	Compact     Structure = "interleaved-compact"
+	Rank9       Structure = "rank9"
+	Poppy       Structure = "poppy"
+	RRR         Structure = "rrr"
+	EliasFano   Structure = "elias-fano"
+)
+
+var Structures []Structure = []Structure{
+	Interleaved, Compact, Rank9, Poppy, RRR, EliasFano,
+}
+
+// Prepares the structure for vec without contributing to the runtime.
+// The returned build func does the precomputation and is timed.
+type StructureBuilder func(vec bit.Vector) (build func() bit.RankSelectVector)
+
+var StructureBuilders = map[Structure]StructureBuilder{
+	Interleaved: func(vec bit.Vector) func() bit.RankSelectVector {
+		// Move base vector into new structure, we could also skip this step and directly read into the interleaved structure.
+		// Since this copying is not needed normally it does not contribute to the recorded runtime
+		intlVec := bit.NewInterleavedVectorNoPrecompute(vec)
+
+		return func() bit.RankSelectVector {
+			intlVec.Precompute()
+			return intlVec
+		}
+	},
+	Compact: func(vec bit.Vector) func() bit.RankSelectVector {
+		return func() bit.RankSelectVector {
+			return bit.NewCompactInterleavedVector(vec)
+		}
+	},
+	Rank9: func(vec bit.Vector) func() bit.RankSelectVector {
+		return func() bit.RankSelectVector {
+			return bit.NewRank9Vector(vec)
+		}
+	},
+	Poppy: func(vec bit.Vector) func() bit.RankSelectVector {
+		return func() bit.RankSelectVector {
+			return bit.NewPoppyVector(vec)
+		}
+	},
+	RRR: func(vec bit.Vector) func() bit.RankSelectVector {
+		return func() bit.RankSelectVector {
+			return bit.NewRRRVector(vec)
+		}
+	},
+	EliasFano: func(vec bit.Vector) func() bit.RankSelectVector {
+		return func() bit.RankSelectVector {
+			return bit.NewEliasFanoVectorFromVector(vec)
+		}
+	},
+}
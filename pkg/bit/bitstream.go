@@ -0,0 +1,57 @@
+package bit
+
+// Append-only stream of integers with up to 64 bits each,
+// packed without gaps into 64 bit words
+type bitStream struct {
+	words []uint64
+	// number of used bits
+	length uint64
+}
+
+// Append the lowest width bits of value
+func (s *bitStream) append(value uint64, width uint8) {
+	if width == 0 {
+		return
+	}
+	if width < 64 {
+		value &= 1<<width - 1
+	}
+
+	offset := s.length % 64
+	if offset == 0 {
+		s.words = append(s.words, 0)
+	}
+	s.words[len(s.words)-1] |= value << offset
+
+	// the rest continues in the next word
+	if offset+uint64(width) > 64 {
+		s.words = append(s.words, value>>(64-offset))
+	}
+
+	s.length += uint64(width)
+}
+
+// Read width bits starting at bit position pos
+func (s *bitStream) get(pos uint64, width uint8) uint64 {
+	if width == 0 {
+		return 0
+	}
+
+	w := pos / 64
+	offset := pos % 64
+
+	value := s.words[w] >> offset
+	if offset+uint64(width) > 64 {
+		value |= s.words[w+1] << (64 - offset)
+	}
+
+	if width < 64 {
+		value &= 1<<width - 1
+	}
+	return value
+}
+
+// Size in bits of the allocated words
+func (s *bitStream) size() uint64 {
+	return uint64(len(s.words)) * 64
+}
@@ -0,0 +1,92 @@
+package bit
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Builder creates a vector bit by bit, without knowing its length up front.
+// The zero value is an empty builder.
+type Builder struct {
+	subvectors []Subvector
+	length     uint64
+}
+
+// Number of bits appended so far
+func (b *Builder) Bits() uint64 {
+	return b.length
+}
+
+// Append a single bit
+func (b *Builder) AppendBit(bit bool) {
+	if b.length%SubvectorBits == 0 {
+		b.subvectors = append(b.subvectors, 0)
+	}
+	if bit {
+		b.subvectors[len(b.subvectors)-1].Set(uint8(b.length % SubvectorBits))
+	}
+	b.length++
+}
+
+// Append the lowest n bits of word, least significant first
+func (b *Builder) AppendBits(word uint64, n uint8) {
+	if uint64(n) > SubvectorBits {
+		panic(fmt.Errorf("%w: %d bits do not fit into a subvector", ErrOutOfRange, n))
+	}
+	if n == 0 {
+		return
+	}
+	if n < 64 {
+		word &= 1<<n - 1
+	}
+
+	offset := b.length % SubvectorBits
+	if offset == 0 {
+		b.subvectors = append(b.subvectors, Subvector(word))
+	} else {
+		b.subvectors[len(b.subvectors)-1] |= Subvector(word << offset)
+		// the part that did not fit into the last subvector
+		if offset+uint64(n) > SubvectorBits {
+			b.subvectors = append(b.subvectors, Subvector(word>>(SubvectorBits-offset)))
+		}
+	}
+	b.length += uint64(n)
+}
+
+// Append count copies of bit
+func (b *Builder) AppendRun(bit bool, count uint64) {
+	var word uint64
+	if bit {
+		word = uint64(SubvectorMax)
+	}
+
+	// fill up the last subvector, then append whole ones
+	if offset := b.length % SubvectorBits; offset != 0 {
+		n := min(SubvectorBits-offset, count)
+		b.AppendBits(word, uint8(n))
+		count -= n
+	}
+
+	for ; count >= SubvectorBits; count -= SubvectorBits {
+		b.subvectors = append(b.subvectors, Subvector(word))
+		b.length += SubvectorBits
+	}
+
+	b.AppendBits(word, uint8(count))
+}
+
+// Copy of the bits appended so far
+func (b *Builder) Vector() Vector {
+	return NewVectorFromSubvectors(slices.Clone(b.subvectors), b.length)
+}
+
+// InterleavedVector of the bits appended so far with computed pre sums
+func (b *Builder) Build() *InterleavedVector {
+	return NewInterleavedVector(NewVectorFromSubvectors(b.subvectors, b.length))
+}
+
+// Remove all appended bits
+func (b *Builder) Reset() {
+	b.subvectors = b.subvectors[:0]
+	b.length = 0
+}
@@ -0,0 +1,64 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderMatchesVector(t *testing.T) {
+	var builder bit.Builder
+	var expected []bool
+
+	for range 2000 {
+		switch rand.Intn(3) {
+		case 0:
+			b := rand.Intn(2) == 1
+			builder.AppendBit(b)
+			expected = append(expected, b)
+		case 1:
+			word, n := rand.Uint64(), uint8(rand.Intn(65))
+			builder.AppendBits(word, n)
+			for k := range n {
+				expected = append(expected, word>>k&1 == 1)
+			}
+		case 2:
+			b, count := rand.Intn(2) == 1, uint64(rand.Intn(200))
+			builder.AppendRun(b, count)
+			for range count {
+				expected = append(expected, b)
+			}
+		}
+	}
+
+	assert.Equal(t, uint64(len(expected)), builder.Bits())
+
+	vec := builder.Vector()
+	interleaved := builder.Build()
+	assert.Equal(t, uint64(len(expected)), vec.Bits())
+	assert.Equal(t, uint64(len(expected)), interleaved.Bits())
+
+	var ones uint64
+	for i, b := range expected {
+		assert.Equal(t, b, vec.Access(uint64(i)))
+		assert.Equal(t, b, interleaved.Access(uint64(i)))
+		assert.Equal(t, ones, interleaved.Rank(true, uint64(i)))
+		if b {
+			ones++
+		}
+	}
+	assert.Equal(t, ones, interleaved.Rank(true, interleaved.Bits()))
+}
+
+func TestBuilderReset(t *testing.T) {
+	var builder bit.Builder
+	builder.AppendRun(true, 100)
+	builder.Reset()
+	builder.AppendBits(0b101, 3)
+
+	assert.Equal(t, bit.NewVector("101").Subvectors(), builder.Vector().Subvectors())
+	assert.Equal(t, uint64(3), builder.Build().Bits())
+	assert.Panics(t, func() { builder.AppendBits(0, 65) })
+}
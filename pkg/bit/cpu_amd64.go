@@ -0,0 +1,57 @@
+package bit
+
+// Implemented in cpu_amd64.s
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+const (
+	// CPUID leaf 1, ECX
+	cpuidOSXSAVE = 1 << 27
+	cpuidAVX     = 1 << 28
+	// CPUID leaf 7, EBX
+	cpuidAVX2 = 1 << 5
+	cpuidBMI2 = 1 << 8
+	// XCR0, the OS saves the SSE and AVX registers
+	xcr0SSEAVX = 0b110
+)
+
+var (
+	hasBMI2 bool
+	hasAVX2 bool
+	// AMD implements PDEP in microcode before Zen 3, slower than the fallback
+	fastPDEP bool
+)
+
+func init() {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 7 {
+		return
+	}
+
+	_, _, ecx1, _ := cpuid(1, 0)
+	_, ebx7, _, _ := cpuid(7, 0)
+
+	hasBMI2 = ebx7&cpuidBMI2 != 0
+	fastPDEP = hasBMI2 && !(isAMD() && family() < 0x19)
+
+	if ecx1&cpuidOSXSAVE != 0 && ecx1&cpuidAVX != 0 {
+		xcr0, _ := xgetbv()
+		hasAVX2 = xcr0&xcr0SSEAVX == xcr0SSEAVX && ebx7&cpuidAVX2 != 0
+	}
+}
+
+func isAMD() bool {
+	_, ebx, ecx, edx := cpuid(0, 0)
+	// "AuthenticAMD"
+	return ebx == 0x68747541 && edx == 0x69746e65 && ecx == 0x444d4163
+}
+
+// Display family of the CPU
+func family() uint32 {
+	eax, _, _, _ := cpuid(1, 0)
+	family := (eax >> 8) & 0xf
+	if family == 0xf {
+		family += (eax >> 20) & 0xff
+	}
+	return family
+}
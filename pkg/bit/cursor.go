@@ -0,0 +1,111 @@
+package bit
+
+import "math/bits"
+
+// Cursor walks the positions of one alpha in [from, to) in increasing order.
+// It loads one subvector at a time and takes its alphas apart by counting
+// trailing zeros, so every position costs a few instructions instead of a
+// Select.
+type Cursor struct {
+	// subvector at index idx of the underlying vector
+	word   func(idx uint64) Subvector
+	length uint64
+	alpha  bool
+
+	to uint64
+	// index of the current subvector and its alphas not returned yet, as ones
+	idx     uint64
+	current uint64
+}
+
+func newCursor(word func(idx uint64) Subvector, length uint64, alpha bool, from, to uint64) *Cursor {
+	c := &Cursor{
+		word:   word,
+		length: length,
+		alpha:  alpha,
+	}
+	c.Reset(from, to)
+	return c
+}
+
+// Restart the cursor on [from, to)
+func (c *Cursor) Reset(from, to uint64) {
+	if err := checkInterval(from, to, c.length); err != nil {
+		panic(err)
+	}
+
+	c.to = to
+	c.idx = from / SubvectorBits
+	c.current = 0
+	if from < to {
+		c.current = c.load(c.idx) & (uint64(SubvectorMax) << (from % SubvectorBits))
+	}
+}
+
+// Subvector idx with the alphas as ones, cut off at to
+func (c *Cursor) load(idx uint64) uint64 {
+	word := uint64(c.word(idx))
+	if !c.alpha {
+		word = ^word
+	}
+	if end := c.to - idx*SubvectorBits; end < SubvectorBits {
+		word &= ^(uint64(SubvectorMax) << end)
+	}
+	return word
+}
+
+// Next position of alpha, false once all of them were returned
+func (c *Cursor) Next() (uint64, bool) {
+	for c.current == 0 {
+		if (c.idx+1)*SubvectorBits >= c.to {
+			return 0, false
+		}
+		c.idx++
+		c.current = c.load(c.idx)
+	}
+
+	pos := c.idx*SubvectorBits + uint64(bits.TrailingZeros64(c.current))
+	// clear the lowest one
+	c.current &= c.current - 1
+	return pos, true
+}
+
+// Call yield for every position of the cursor until it returns false
+func (c *Cursor) forEach(yield func(position uint64) bool) {
+	for pos, ok := c.Next(); ok && yield(pos); pos, ok = c.Next() {
+	}
+}
+
+// Cursor over the positions of alpha in [from, to)
+func (b Vector) Cursor(alpha bool, from, to uint64) *Cursor {
+	return newCursor(func(idx uint64) Subvector {
+		return b.subvectors[idx]
+	}, b.length, alpha, from, to)
+}
+
+// Call yield for every one in [from, to) until it returns false
+func (b Vector) ForEachOne(from, to uint64, yield func(position uint64) bool) {
+	b.Cursor(true, from, to).forEach(yield)
+}
+
+// Call yield for every zero in [from, to) until it returns false
+func (b Vector) ForEachZero(from, to uint64, yield func(position uint64) bool) {
+	b.Cursor(false, from, to).forEach(yield)
+}
+
+// Cursor over the positions of alpha in [from, to)
+func (i *InterleavedVector) Cursor(alpha bool, from, to uint64) *Cursor {
+	return newCursor(func(idx uint64) Subvector {
+		return i.vec[idx/InterleavedSubvectorCount].Vec[idx%InterleavedSubvectorCount]
+	}, i.length, alpha, from, to)
+}
+
+// Call yield for every one in [from, to) until it returns false
+func (i *InterleavedVector) ForEachOne(from, to uint64, yield func(position uint64) bool) {
+	i.Cursor(true, from, to).forEach(yield)
+}
+
+// Call yield for every zero in [from, to) until it returns false
+func (i *InterleavedVector) ForEachZero(from, to uint64, yield func(position uint64) bool) {
+	i.Cursor(false, from, to).forEach(yield)
+}
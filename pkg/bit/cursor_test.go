@@ -0,0 +1,86 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+// Positions of alpha in [from, to) by accessing every bit
+func naivePositions(vec bit.Vector, alpha bool, from, to uint64) []uint64 {
+	positions := []uint64{}
+	for i := from; i < to; i++ {
+		if vec.Access(i) == alpha {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+func collect(forEach func(from, to uint64, yield func(uint64) bool), from, to uint64) []uint64 {
+	positions := []uint64{}
+	forEach(from, to, func(position uint64) bool {
+		positions = append(positions, position)
+		return true
+	})
+	return positions
+}
+
+func TestForEachVsNaive(t *testing.T) {
+	vec := randomVector(50)
+	vec = bit.NewVectorFromSubvectors(vec.Subvectors(), vec.Bits()-13)
+	interleaved := bit.NewInterleavedVector(vec)
+
+	ranges := [][2]uint64{{0, 0}, {0, vec.Bits()}, {5, 5}, {3, 64}, {64, 128}, {vec.Bits() - 1, vec.Bits()}}
+	for range 50 {
+		from := uint64(rand.Int63n(int64(vec.Bits())))
+		to := from + uint64(rand.Int63n(int64(vec.Bits()-from+1)))
+		ranges = append(ranges, [2]uint64{from, to})
+	}
+
+	for _, r := range ranges {
+		from, to := r[0], r[1]
+		ones := naivePositions(vec, true, from, to)
+		zeros := naivePositions(vec, false, from, to)
+
+		assert.Equal(t, ones, collect(vec.ForEachOne, from, to), "[%d, %d)", from, to)
+		assert.Equal(t, zeros, collect(vec.ForEachZero, from, to), "[%d, %d)", from, to)
+		assert.Equal(t, ones, collect(interleaved.ForEachOne, from, to), "[%d, %d)", from, to)
+		assert.Equal(t, zeros, collect(interleaved.ForEachZero, from, to), "[%d, %d)", from, to)
+	}
+}
+
+func TestForEachStops(t *testing.T) {
+	vec := bit.NewVector("0110111")
+
+	var positions []uint64
+	vec.ForEachOne(0, vec.Bits(), func(position uint64) bool {
+		positions = append(positions, position)
+		return len(positions) < 3
+	})
+
+	assert.Equal(t, []uint64{1, 2, 4}, positions)
+}
+
+func TestCursorReset(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(bit.NewVector("1001101"))
+
+	cursor := interleaved.Cursor(false, 0, 7)
+	pos, ok := cursor.Next()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), pos)
+
+	cursor.Reset(3, 6)
+	pos, ok = cursor.Next()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), pos)
+	_, ok = cursor.Next()
+	assert.False(t, ok)
+	_, ok = cursor.Next()
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { cursor.Reset(4, 3) })
+	assert.Panics(t, func() { cursor.Reset(0, 8) })
+}
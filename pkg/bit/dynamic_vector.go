@@ -0,0 +1,475 @@
+package bit
+
+import "math/bits"
+
+const (
+	// A leaf is split when it grows beyond this many bits
+	dynamicLeafMaxBits uint64 = 4096
+	// Bulk loaded leaves are half full, so inserts do not split right away
+	dynamicLeafFillBits = dynamicLeafMaxBits / 2
+	// Approximate size of the fields of one node
+	dynamicNodeBits uint64 = 7 * 64
+)
+
+var _ RankSelectVector = (*DynamicVector)(nil)
+
+// Bit vector that supports inserting and deleting bits at any position.
+// The bits are kept in leaves of up to 4096 bits, which are the leaves of an
+// AVL tree. Every node knows the number of bits and ones below it, so access,
+// rank, select, insert and delete walk down one path in O(log n).
+type DynamicVector struct {
+	root *dynamicNode
+}
+
+type dynamicNode struct {
+	// both nil for a leaf
+	left, right *dynamicNode
+	height      int
+
+	// number of bits and ones in this subtree
+	size, ones uint64
+
+	// bits of a leaf, packed from position 0
+	bits []Subvector
+}
+
+func (n *dynamicNode) isLeaf() bool {
+	return n.left == nil
+}
+
+// Number of alphas in the subtree
+func (n *dynamicNode) count(alpha bool) uint64 {
+	if alpha {
+		return n.ones
+	}
+	return n.size - n.ones
+}
+
+// Recalculate the aggregates of an inner node from its children
+func (n *dynamicNode) update() {
+	n.size = n.left.size + n.right.size
+	n.ones = n.left.ones + n.right.ones
+	n.height = max(n.left.height, n.right.height) + 1
+}
+
+// Create an empty dynamic vector
+func NewEmptyDynamicVector() *DynamicVector {
+	return &DynamicVector{
+		root: &dynamicNode{},
+	}
+}
+
+// Create a dynamic vector holding a copy of vec
+func NewDynamicVector(vec Vector) *DynamicVector {
+	if vec.Bits() == 0 {
+		return NewEmptyDynamicVector()
+	}
+
+	subvectors := vec.Subvectors()
+	leafWords := dynamicLeafFillBits / SubvectorBits
+
+	leaves := make([]*dynamicNode, 0, (uint64(len(subvectors))+leafWords-1)/leafWords)
+	for start := uint64(0); start < uint64(len(subvectors)); start += leafWords {
+		end := min(start+leafWords, uint64(len(subvectors)))
+
+		leaf := &dynamicNode{
+			size: min(end*SubvectorBits, vec.Bits()) - start*SubvectorBits,
+			ones: onesCount(subvectors[start:end]),
+			bits: make([]Subvector, end-start, dynamicLeafMaxBits/SubvectorBits+1),
+		}
+		copy(leaf.bits, subvectors[start:end])
+
+		leaves = append(leaves, leaf)
+	}
+
+	return &DynamicVector{
+		root: buildDynamicTree(leaves),
+	}
+}
+
+// Perfectly balanced tree over the leaves
+func buildDynamicTree(leaves []*dynamicNode) *dynamicNode {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	mid := len(leaves) / 2
+	n := &dynamicNode{
+		left:  buildDynamicTree(leaves[:mid]),
+		right: buildDynamicTree(leaves[mid:]),
+	}
+	n.update()
+	return n
+}
+
+// Copy the bits into a static vector
+func (d *DynamicVector) Vector() Vector {
+	var stream bitStream
+
+	var collect func(n *dynamicNode)
+	collect = func(n *dynamicNode) {
+		if !n.isLeaf() {
+			collect(n.left)
+			collect(n.right)
+			return
+		}
+
+		for w := uint64(0); w*SubvectorBits < n.size; w++ {
+			stream.append(uint64(n.bits[w]), uint8(min(SubvectorBits, n.size-w*SubvectorBits)))
+		}
+	}
+	collect(d.root)
+
+	subvectors := make([]Subvector, subvectorCount(d.root.size))
+	for i := range subvectors {
+		subvectors[i] = Subvector(stream.words[i])
+	}
+
+	return NewVectorFromSubvectors(subvectors, d.root.size)
+}
+
+// Copy the bits into a static interleaved vector
+func (d *DynamicVector) InterleavedVector() *InterleavedVector {
+	return NewInterleavedVector(d.Vector())
+}
+
+func dynamicHeight(n *dynamicNode) int {
+	return n.height
+}
+
+func rotateRight(n *dynamicNode) *dynamicNode {
+	l := n.left
+	n.left = l.right
+	n.update()
+	l.right = n
+	l.update()
+	return l
+}
+
+func rotateLeft(n *dynamicNode) *dynamicNode {
+	r := n.right
+	n.right = r.left
+	n.update()
+	r.left = n
+	r.update()
+	return r
+}
+
+// Update n and restore the AVL property with at most two rotations
+func rebalance(n *dynamicNode) *dynamicNode {
+	n.update()
+
+	switch balance := dynamicHeight(n.left) - dynamicHeight(n.right); {
+	case balance > 1:
+		if dynamicHeight(n.left.left) < dynamicHeight(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if dynamicHeight(n.right.right) < dynamicHeight(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+
+	return n
+}
+
+// Insert b so that it ends up at position
+func (d *DynamicVector) Insert(position uint64, b bool) {
+	if err := checkRank(position, d.root.size); err != nil {
+		panic(err)
+	}
+
+	d.root = dynamicInsert(d.root, position, b)
+}
+
+func dynamicInsert(n *dynamicNode, position uint64, b bool) *dynamicNode {
+	if n.isLeaf() {
+		leafInsert(n, position, b)
+
+		if n.size > dynamicLeafMaxBits {
+			return splitLeaf(n)
+		}
+		return n
+	}
+
+	if position < n.left.size {
+		n.left = dynamicInsert(n.left, position, b)
+	} else {
+		n.right = dynamicInsert(n.right, position-n.left.size, b)
+	}
+
+	return rebalance(n)
+}
+
+// Shift everything from position one to the back and put b there
+func leafInsert(n *dynamicNode, position uint64, b bool) {
+	if n.size%SubvectorBits == 0 {
+		n.bits = append(n.bits, 0)
+	}
+
+	w := position / SubvectorBits
+	offset := position % SubvectorBits
+
+	for i := len(n.bits) - 1; uint64(i) > w; i-- {
+		n.bits[i] = n.bits[i]<<1 | n.bits[i-1]>>(SubvectorBits-1)
+	}
+
+	low := n.bits[w] & ^(SubvectorMax << offset)
+	high := n.bits[w] & (SubvectorMax << offset)
+	n.bits[w] = low | high<<1
+
+	if b {
+		n.bits[w] |= 1 << offset
+		n.ones++
+	}
+	n.size++
+}
+
+// Split a full leaf at a subvector boundary into two leaves below a new node
+func splitLeaf(n *dynamicNode) *dynamicNode {
+	half := uint64(len(n.bits)) / 2
+
+	right := &dynamicNode{
+		size: n.size - half*SubvectorBits,
+		ones: onesCount(n.bits[half:]),
+		bits: make([]Subvector, uint64(len(n.bits))-half, dynamicLeafMaxBits/SubvectorBits+1),
+	}
+	copy(right.bits, n.bits[half:])
+
+	left := &dynamicNode{
+		size: half * SubvectorBits,
+		ones: n.ones - right.ones,
+		bits: n.bits[:half],
+	}
+
+	parent := &dynamicNode{
+		left:  left,
+		right: right,
+	}
+	parent.update()
+	return parent
+}
+
+// Remove the bit at position and return it
+func (d *DynamicVector) Delete(position uint64) bool {
+	if err := checkAccess(position, d.root.size); err != nil {
+		panic(err)
+	}
+
+	var removed bool
+	d.root = dynamicDelete(d.root, position, &removed)
+	return removed
+}
+
+func dynamicDelete(n *dynamicNode, position uint64, removed *bool) *dynamicNode {
+	if n.isLeaf() {
+		*removed = leafDelete(n, position)
+		return n
+	}
+
+	if position < n.left.size {
+		n.left = dynamicDelete(n.left, position, removed)
+	} else {
+		n.right = dynamicDelete(n.right, position-n.left.size, removed)
+	}
+
+	// drop empty leaves and merge small neighbours
+	if n.left.size == 0 {
+		return n.right
+	}
+	if n.right.size == 0 {
+		return n.left
+	}
+	if n.left.isLeaf() && n.right.isLeaf() && n.left.size+n.right.size <= dynamicLeafFillBits {
+		return mergeLeaves(n.left, n.right)
+	}
+
+	return rebalance(n)
+}
+
+// Remove the bit at position by shifting everything after it to the front
+func leafDelete(n *dynamicNode, position uint64) bool {
+	w := position / SubvectorBits
+	offset := position % SubvectorBits
+
+	b := n.bits[w].Access(uint8(offset))
+
+	low := n.bits[w] & ^(SubvectorMax << offset)
+	high := (n.bits[w] >> 1) & (SubvectorMax << offset)
+	n.bits[w] = low | high
+
+	for i := w; i+1 < uint64(len(n.bits)); i++ {
+		n.bits[i] |= n.bits[i+1] << (SubvectorBits - 1)
+		n.bits[i+1] >>= 1
+	}
+
+	n.size--
+	if b {
+		n.ones--
+	}
+	if n.size%SubvectorBits == 0 {
+		n.bits = n.bits[:len(n.bits)-1]
+	}
+
+	return b
+}
+
+func mergeLeaves(left, right *dynamicNode) *dynamicNode {
+	for w := uint64(0); w*SubvectorBits < right.size; w++ {
+		word := right.bits[w]
+		for i := uint64(0); i < min(SubvectorBits, right.size-w*SubvectorBits); i++ {
+			leafInsert(left, left.size, word.Access(uint8(i)))
+		}
+	}
+	return left
+}
+
+// Number of alphas in the whole vector
+func (d *DynamicVector) count(alpha bool) uint64 {
+	return d.root.count(alpha)
+}
+
+// Access implements RankSelectVector.
+func (d *DynamicVector) Access(position uint64) bool {
+	if err := checkAccess(position, d.root.size); err != nil {
+		panic(err)
+	}
+
+	n := d.root
+	for !n.isLeaf() {
+		if position < n.left.size {
+			n = n.left
+		} else {
+			position -= n.left.size
+			n = n.right
+		}
+	}
+
+	return n.bits[position/SubvectorBits].Access(uint8(position % SubvectorBits))
+}
+
+// Rank implements RankSelectVector.
+func (d *DynamicVector) Rank(alpha bool, position uint64) uint64 {
+	if err := checkRank(position, d.root.size); err != nil {
+		panic(err)
+	}
+
+	if position == d.root.size {
+		return d.count(alpha)
+	}
+
+	var rank uint64
+	n, p := d.root, position
+	for !n.isLeaf() {
+		if p < n.left.size {
+			n = n.left
+		} else {
+			rank += n.left.ones
+			p -= n.left.size
+			n = n.right
+		}
+	}
+
+	w := p / SubvectorBits
+	rank += onesCount(n.bits[:w]) + uint64(bits.OnesCount64(uint64(n.bits[w] & ^(SubvectorMax<<(p%SubvectorBits)))))
+
+	if alpha {
+		return rank
+	}
+	return position - rank
+}
+
+// Select implements RankSelectVector.
+func (d *DynamicVector) Select(alpha bool, n uint64) uint64 {
+	if err := checkSelect(alpha, n, d.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	var position uint64
+	node := d.root
+	for !node.isLeaf() {
+		if left := node.left.count(alpha); n <= left {
+			node = node.left
+		} else {
+			n -= left
+			position += node.left.size
+			node = node.right
+		}
+	}
+
+	return position + selectInSubvectors(node.bits, alpha, n)
+}
+
+// TryAccess implements RankSelectVector.
+func (d *DynamicVector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, d.root.size); err != nil {
+		return false, err
+	}
+	return d.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (d *DynamicVector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, d.root.size); err != nil {
+		return 0, err
+	}
+	return d.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (d *DynamicVector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := checkSelect(alpha, n, d.count(alpha)); err != nil {
+		return 0, err
+	}
+	return d.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (d *DynamicVector) RankRange(alpha bool, l, r uint64) uint64 {
+	return must(d.TryRankRange(alpha, l, r))
+}
+
+// SelectFrom implements RankSelectVector.
+func (d *DynamicVector) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(d.TrySelectFrom(alpha, p, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (d *DynamicVector) TryRankRange(alpha bool, l, r uint64) (uint64, error) {
+	return tryRankRange(d, alpha, l, r)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (d *DynamicVector) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	return trySelectFrom(d, alpha, p, k)
+}
+
+// Logical number of bits
+func (d *DynamicVector) Bits() uint64 {
+	return d.root.size
+}
+
+// Overhead implements RankSelectVector.
+func (d *DynamicVector) Overhead() uint64 {
+	// nodes, unused leaf capacity and padding
+	return d.Size() - d.root.size
+}
+
+// Size implements RankSelectVector.
+func (d *DynamicVector) Size() uint64 {
+	var size uint64
+
+	var walk func(n *dynamicNode)
+	walk = func(n *dynamicNode) {
+		size += dynamicNodeBits + uint64(cap(n.bits))*SubvectorBits
+		if !n.isLeaf() {
+			walk(n.left)
+			walk(n.right)
+		}
+	}
+	walk(d.root)
+
+	return size
+}
@@ -0,0 +1,92 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+// Check every query of the dynamic vector against a plain bool slice
+func assertDynamicEquals(t *testing.T, expected []bool, d *bit.DynamicVector) {
+	t.Helper()
+
+	assert.Equal(t, uint64(len(expected)), d.Bits())
+
+	var ones, zeros uint64
+	for i, b := range expected {
+		if !assert.Equal(t, b, d.Access(uint64(i)), i) {
+			return
+		}
+		assert.Equal(t, ones, d.Rank(true, uint64(i)), i)
+		assert.Equal(t, zeros, d.Rank(false, uint64(i)), i)
+
+		if b {
+			ones++
+			assert.Equal(t, uint64(i), d.Select(true, ones))
+		} else {
+			zeros++
+			assert.Equal(t, uint64(i), d.Select(false, zeros))
+		}
+	}
+	assert.Equal(t, ones, d.Rank(true, d.Bits()))
+}
+
+func TestDynamicVectorInsertDelete(t *testing.T) {
+	d := bit.NewEmptyDynamicVector()
+	var expected []bool
+
+	for i := 0; i < 20_000; i++ {
+		b := rand.Intn(3) == 0
+		pos := rand.Intn(len(expected) + 1)
+
+		d.Insert(uint64(pos), b)
+		expected = append(expected[:pos], append([]bool{b}, expected[pos:]...)...)
+	}
+	assertDynamicEquals(t, expected, d)
+
+	for i := 0; i < 15_000; i++ {
+		pos := rand.Intn(len(expected))
+
+		assert.Equal(t, expected[pos], d.Delete(uint64(pos)))
+		expected = append(expected[:pos], expected[pos+1:]...)
+	}
+	assertDynamicEquals(t, expected, d)
+
+	for len(expected) > 0 {
+		d.Delete(0)
+		expected = expected[1:]
+	}
+	assertDynamicEquals(t, expected, d)
+}
+
+func TestDynamicVectorConversion(t *testing.T) {
+	vec := randomDensityVector(100_003, 400)
+
+	d := bit.NewDynamicVector(vec)
+	assert.Equal(t, vec, d.Vector())
+
+	// insert at the front and remove it again, everything has to shift twice
+	d.Insert(0, true)
+	assert.Equal(t, vec.Ones()+1, d.Rank(true, d.Bits()))
+	assert.True(t, d.Delete(0))
+
+	interleaved := d.InterleavedVector()
+	assert.Equal(t, vec, interleaved.Vector())
+	assert.Equal(t, vec, bit.NewDynamicVector(interleaved.Vector()).Vector())
+}
+
+func TestDynamicVectorErrors(t *testing.T) {
+	d := bit.NewDynamicVector(bit.NewVector("0110"))
+
+	assert.Panics(t, func() {
+		d.Insert(5, true)
+	})
+	assert.Panics(t, func() {
+		d.Delete(4)
+	})
+
+	_, err := d.TrySelect(true, 3)
+	assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+}
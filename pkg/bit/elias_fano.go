@@ -0,0 +1,260 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+var ErrUnsortedPositions = errors.New("positions are not strictly increasing")
+
+var _ RankSelectVector = (*EliasFanoVector)(nil)
+
+// Elias–Fano representation of the positions of the ones.
+// Every position is split into l lower bits, stored verbatim, and the upper
+// bits, stored in unary as gaps in an InterleavedVector. The i'th one is the
+// i'th one in the upper vector, its bucket is the number of zeros before it.
+// With n ones in u bits this needs about n·(2 + log2(u/n)) bits.
+type EliasFanoVector struct {
+	lower      bitStream
+	lowerWidth uint8
+	upper      *InterleavedVector
+
+	length uint64
+	ones   uint64
+}
+
+// Build from strictly increasing positions of the ones in a vector of length bits
+func NewEliasFanoVector(positions []uint64, length uint64) (*EliasFanoVector, error) {
+	for i, pos := range positions {
+		if pos >= length {
+			return nil, fmt.Errorf("%w: position %d, length %d", ErrOutOfRange, pos, length)
+		}
+		if i > 0 && positions[i-1] >= pos {
+			return nil, fmt.Errorf("%w: %d after %d", ErrUnsortedPositions, pos, positions[i-1])
+		}
+	}
+
+	ef := newEliasFanoVector(uint64(len(positions)), length)
+	for i, pos := range positions {
+		ef.add(uint64(i), pos)
+	}
+	ef.index()
+
+	return ef, nil
+}
+
+// Build from the ones of vec
+func NewEliasFanoVectorFromVector(vec Vector) *EliasFanoVector {
+	ef := newEliasFanoVector(vec.Ones(), vec.Bits())
+
+	var i uint64
+	for w, sv := range vec.Subvectors() {
+		for sv != 0 {
+			ef.add(i, uint64(w)*SubvectorBits+uint64(bits.TrailingZeros64(uint64(sv))))
+			sv &= sv - 1
+			i++
+		}
+	}
+	ef.index()
+
+	return ef
+}
+
+func newEliasFanoVector(ones, length uint64) *EliasFanoVector {
+	var lowerWidth uint8
+	if ones > 0 && length > ones {
+		lowerWidth = uint8(bits.Len64(length/ones) - 1)
+	}
+
+	upper := MakeVector(ones + length>>lowerWidth + 1)
+
+	return &EliasFanoVector{
+		lowerWidth: lowerWidth,
+		upper:      NewInterleavedVectorNoPrecompute(upper),
+		length:     length,
+		ones:       ones,
+	}
+}
+
+// Build the rank and select index over the filled upper bits
+func (e *EliasFanoVector) index() {
+	e.upper.Precompute()
+	e.upper.SetSelectSampleRate(DefaultSelectSampleRate)
+}
+
+// Store pos as the i'th one (0 based)
+func (e *EliasFanoVector) add(i, pos uint64) {
+	e.lower.append(pos, e.lowerWidth)
+	e.upper.Set(pos>>e.lowerWidth + i)
+}
+
+// Position of the i'th one (0 based)
+func (e *EliasFanoVector) position(i uint64) uint64 {
+	high := e.upper.Select(true, i+1) - i
+	return high<<e.lowerWidth | e.lower.get(i*uint64(e.lowerWidth), e.lowerWidth)
+}
+
+// Number of alphas in the whole vector
+func (e *EliasFanoVector) count(alpha bool) uint64 {
+	if alpha {
+		return e.ones
+	}
+	return e.length - e.ones
+}
+
+// Number of ones before position
+func (e *EliasFanoVector) rankOnes(position uint64) uint64 {
+	if position == e.length {
+		return e.ones
+	}
+
+	bucket := position >> e.lowerWidth
+	low := position & (1<<e.lowerWidth - 1)
+
+	// skip all ones in lower buckets, each bucket ends with a zero
+	var j, i uint64
+	if bucket > 0 {
+		j = e.upper.Select(false, bucket) + 1
+		i = j - bucket
+	}
+
+	// ones in the same bucket are sorted by their lower bits
+	for ; i < e.ones && e.upper.Access(j); i, j = i+1, j+1 {
+		if e.lower.get(i*uint64(e.lowerWidth), e.lowerWidth) >= low {
+			break
+		}
+	}
+
+	return i
+}
+
+// Access implements RankSelectVector.
+func (e *EliasFanoVector) Access(position uint64) bool {
+	if err := checkAccess(position, e.length); err != nil {
+		panic(err)
+	}
+
+	i := e.rankOnes(position)
+	return i < e.ones && e.position(i) == position
+}
+
+// Rank implements RankSelectVector.
+func (e *EliasFanoVector) Rank(alpha bool, position uint64) uint64 {
+	if err := checkRank(position, e.length); err != nil {
+		panic(err)
+	}
+
+	rank := e.rankOnes(position)
+	if alpha {
+		return rank
+	}
+	return position - rank
+}
+
+// Select implements RankSelectVector.
+func (e *EliasFanoVector) Select(alpha bool, n uint64) uint64 {
+	if err := checkSelect(alpha, n, e.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	if alpha {
+		return e.position(n - 1)
+	}
+
+	// the i'th one has position(i) - i zeros before it,
+	// the n'th zero comes after all ones with fewer than n zeros before them
+	ones := uint64(sort.Search(int(e.ones), func(i int) bool {
+		return e.position(uint64(i))-uint64(i) >= n
+	}))
+
+	return n - 1 + ones
+}
+
+// First one at or after position, false if there is none
+func (e *EliasFanoVector) NextGEQ(position uint64) (uint64, bool) {
+	if position >= e.length {
+		return 0, false
+	}
+
+	i := e.rankOnes(position)
+	if i == e.ones {
+		return 0, false
+	}
+	return e.position(i), true
+}
+
+// Last one at or before position, false if there is none
+func (e *EliasFanoVector) PrevLEQ(position uint64) (uint64, bool) {
+	i := e.ones
+	if position < e.length {
+		i = e.rankOnes(position + 1)
+	}
+
+	if i == 0 {
+		return 0, false
+	}
+	return e.position(i - 1), true
+}
+
+// TryAccess implements RankSelectVector.
+func (e *EliasFanoVector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, e.length); err != nil {
+		return false, err
+	}
+	return e.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (e *EliasFanoVector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, e.length); err != nil {
+		return 0, err
+	}
+	return e.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (e *EliasFanoVector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := checkSelect(alpha, n, e.count(alpha)); err != nil {
+		return 0, err
+	}
+	return e.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (e *EliasFanoVector) RankRange(alpha bool, l, r uint64) uint64 {
+	return must(e.TryRankRange(alpha, l, r))
+}
+
+// SelectFrom implements RankSelectVector.
+func (e *EliasFanoVector) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(e.TrySelectFrom(alpha, p, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (e *EliasFanoVector) TryRankRange(alpha bool, l, r uint64) (uint64, error) {
+	return tryRankRange(e, alpha, l, r)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (e *EliasFanoVector) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	return trySelectFrom(e, alpha, p, k)
+}
+
+// Logical number of bits
+func (e *EliasFanoVector) Bits() uint64 {
+	return e.length
+}
+
+// Overhead implements RankSelectVector.
+// Only the rank and select index of the upper bits, the lower and upper bits
+// are the encoded vector itself.
+func (e *EliasFanoVector) Overhead() uint64 {
+	return e.upper.Overhead()
+}
+
+// Size implements RankSelectVector.
+func (e *EliasFanoVector) Size() uint64 {
+	return e.lower.size() + e.upper.Size()
+}
@@ -0,0 +1,94 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEliasFanoFromPositions(t *testing.T) {
+	positions := []uint64{0, 3, 4, 100, 1000, 1001, 4095}
+
+	ef, err := bit.NewEliasFanoVector(positions, 4096)
+	require.NoError(t, err)
+
+	for i, pos := range positions {
+		assert.Equal(t, pos, ef.Select(true, uint64(i+1)))
+		assert.True(t, ef.Access(pos))
+		assert.Equal(t, uint64(i), ef.Rank(true, pos))
+	}
+	assert.False(t, ef.Access(5))
+	assert.Equal(t, uint64(len(positions)), ef.Rank(true, 4096))
+	assert.Equal(t, uint64(1), ef.Select(false, 1))
+	assert.Equal(t, uint64(5), ef.Select(false, 3))
+}
+
+func TestEliasFanoInvalidPositions(t *testing.T) {
+	_, err := bit.NewEliasFanoVector([]uint64{1, 5, 5}, 10)
+	assert.ErrorIs(t, err, bit.ErrUnsortedPositions)
+
+	_, err = bit.NewEliasFanoVector([]uint64{1, 10}, 10)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+}
+
+func TestEliasFanoNextGEQPrevLEQ(t *testing.T) {
+	vec := randomDensityVector(50_000, 20)
+	ef := bit.NewEliasFanoVectorFromVector(vec)
+
+	// naive successor and predecessor
+	next := func(x uint64) (uint64, bool) {
+		for i := x; i < vec.Bits(); i++ {
+			if vec.Access(i) {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	prev := func(x uint64) (uint64, bool) {
+		for i := int64(min(x, vec.Bits()-1)); i >= 0; i-- {
+			if vec.Access(uint64(i)) {
+				return uint64(i), true
+			}
+		}
+		return 0, false
+	}
+
+	queries := []uint64{0, vec.Bits() - 1, vec.Bits(), vec.Bits() + 10}
+	for i := 0; i < 500; i++ {
+		queries = append(queries, uint64(rand.Int63n(int64(vec.Bits()))))
+	}
+
+	for _, x := range queries {
+		expected, expectedOk := next(x)
+		actual, ok := ef.NextGEQ(x)
+		assert.Equal(t, expectedOk, ok, x)
+		assert.Equal(t, expected, actual, x)
+
+		expected, expectedOk = prev(x)
+		actual, ok = ef.PrevLEQ(x)
+		assert.Equal(t, expectedOk, ok, x)
+		assert.Equal(t, expected, actual, x)
+	}
+}
+
+func TestEliasFanoSpace(t *testing.T) {
+	const length = 1 << 22
+	ef := bit.NewEliasFanoVectorFromVector(randomDensityVector(length, 1))
+
+	// about n(2 + log(u/n)) bits, far less than the plain vector
+	assert.Less(t, ef.Size(), uint64(length/50))
+}
+
+func TestEliasFanoEmpty(t *testing.T) {
+	ef, err := bit.NewEliasFanoVector(nil, 100)
+	require.NoError(t, err)
+
+	_, ok := ef.NextGEQ(0)
+	assert.False(t, ok)
+	_, ok = ef.PrevLEQ(99)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(42), ef.Select(false, 43))
+}
@@ -0,0 +1,57 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// Position outside of the vector or an occurrence number of 0
+	ErrOutOfRange = errors.New("out of range")
+	// Select asked for more alphas than the vector contains
+	ErrNotEnoughOccurrences = errors.New("not enough occurrences")
+	// Two vectors combined bitwise do not have the same logical length
+	ErrLengthMismatch = errors.New("length mismatch")
+)
+
+// Access is valid for positions in [0, length)
+func checkAccess(position, length uint64) error {
+	if position >= length {
+		return fmt.Errorf("%w: position %d, length %d", ErrOutOfRange, position, length)
+	}
+	return nil
+}
+
+// Rank is valid for positions in [0, length]
+func checkRank(position, length uint64) error {
+	if position > length {
+		return fmt.Errorf("%w: position %d, length %d", ErrOutOfRange, position, length)
+	}
+	return nil
+}
+
+// Select is valid for occurrences in [1, count]
+func checkSelect(alpha bool, n, count uint64) error {
+	if n == 0 {
+		return fmt.Errorf("%w: occurrences start at 1", ErrOutOfRange)
+	}
+	if n > count {
+		return fmt.Errorf("%w: %d. %s requested, only %d available", ErrNotEnoughOccurrences, n, alphaName(alpha), count)
+	}
+	return nil
+}
+
+// An interval [from, to) is valid inside [0, length]
+func checkInterval(from, to, length uint64) error {
+	if from > to || to > length {
+		return fmt.Errorf("%w: range [%d, %d), length %d", ErrOutOfRange, from, to, length)
+	}
+	return nil
+}
+
+func alphaName(alpha bool) string {
+	if alpha {
+		return "one"
+	}
+	return "zero"
+}
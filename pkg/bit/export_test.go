@@ -0,0 +1,44 @@
+package bit
+
+// Select implementations that run on this CPU
+func Select64Variants() map[string]func(x uint64, n uint8) uint8 {
+	variants := map[string]func(x uint64, n uint8) uint8{
+		"table":     selectTable,
+		"broadword": selectBroadword,
+	}
+	if pdepSelect != nil {
+		variants["pdep"] = pdepSelect
+	}
+	return variants
+}
+
+// Select64 variant with the n check of OneSelect64
+func CheckedSelect64(variant func(x uint64, n uint8) uint8) func(x uint64, n uint8) uint8 {
+	return func(x uint64, n uint8) uint8 {
+		return checkedSelect64(variant, x, n)
+	}
+}
+
+// Popcount implementations that run on this CPU
+func PopcountVariants() map[string]func(subvectors []Subvector) uint64 {
+	variants := map[string]func(subvectors []Subvector) uint64{
+		"go": onesCountGo,
+	}
+	if popcountBulk != nil {
+		variants["bulk"] = popcountBulk
+	}
+	return variants
+}
+
+// Line popcount implementations that run on this CPU
+func LinePopcountVariants() map[string]func(lines []InterleavedVectorLine) {
+	return map[string]func(lines []InterleavedVectorLine){
+		"go":       linePopcountsGo,
+		"selected": linePopcounts,
+	}
+}
+
+// Compact interleaved vector with 2^shift lines per superblock
+func NewCompactInterleavedVectorWithSuperblockShift(vec Vector, shift uint) *CompactInterleavedVector {
+	return newCompactInterleavedVector(vec, shift)
+}
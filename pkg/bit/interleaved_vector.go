@@ -19,67 +19,118 @@ var _ Setable = (*InterleavedVector)(nil)
 // Create the interleaved datastructure by copying the vec Vector
 // Also create the pre sums
 func NewInterleavedVector(vec Vector) *InterleavedVector {
-	lines := uint64(math.Ceil(float64(len(vec)) / float64(InterleavedSubvectorCount)))
+	intlVec := NewInterleavedVectorNoPrecompute(vec)
+	intlVec.Precompute()
 
-	intlVec := &InterleavedVector{
-		vec: make([]InterleavedVectorLine, lines),
-	}
-
-	var totalSum uint64
-
-	for i := range lines {
-		startPos := i * InterleavedSubvectorCount
-		endPos := startPos + InterleavedSubvectorCount
-
-		if endPos > uint64(len(vec)) {
-			endPos = uint64(len(vec))
-		}
-
-		lineSlice := vec[startPos:endPos]
-		copy(intlVec.vec[i].Vec[:], lineSlice)
-
-		intlVec.vec[i].PreSum = totalSum
+	return intlVec
+}
 
-		totalSum += Vector(lineSlice).Ones()
-	}
+func NewInterleavedVectorNoPrecompute(vec Vector) *InterleavedVector {
+	intlVec := newInterleavedVectorLines(vec)
+	intlVec.copyLines(vec.Subvectors(), 0, len(intlVec.vec))
 
 	return intlVec
 }
 
-func NewInterleavedVectorNoPrecompute(vec Vector) *InterleavedVector {
-	lines := uint64(math.Ceil(float64(len(vec)) / float64(InterleavedSubvectorCount)))
+// Allocate the lines for vec without filling them
+func newInterleavedVectorLines(vec Vector) *InterleavedVector {
+	lines := uint64(math.Ceil(float64(len(vec.Subvectors())) / float64(InterleavedSubvectorCount)))
 
-	intlVec := &InterleavedVector{
-		vec: make([]InterleavedVectorLine, lines),
+	return &InterleavedVector{
+		vec:    make([]InterleavedVectorLine, lines),
+		length: vec.Bits(),
+		// no pre sums yet, Rank and Select need a Precompute first
+		dirtyFrom: 0,
 	}
+}
 
-	for i := range lines {
+// Copy the subvectors of the lines [from, to)
+func (intlVec *InterleavedVector) copyLines(subvectors []Subvector, from, to int) {
+	for i := uint64(from); i < uint64(to); i++ {
 		startPos := i * InterleavedSubvectorCount
 		endPos := startPos + InterleavedSubvectorCount
 
-		if endPos > uint64(len(vec)) {
-			endPos = uint64(len(vec))
+		if endPos > uint64(len(subvectors)) {
+			endPos = uint64(len(subvectors))
 		}
 
-		lineSlice := vec[startPos:endPos]
+		lineSlice := subvectors[startPos:endPos]
 		copy(intlVec.vec[i].Vec[:], lineSlice)
 	}
-
-	return intlVec
 }
 
 type InterleavedVector struct {
 	vec []InterleavedVectorLine
+
+	// logical number of bits, the rest of the last line is padding
+	length uint64
+	// total number of ones, set by Precompute
+	ones uint64
+
+	// lines are backed by a read-only mapping
+	readOnly bool
+
+	// every sampleRate'th alpha is sampled, 0 disables sampling
+	sampleRate uint64
+	// line of every sampled one (index 1) and zero (index 0)
+	selectSamples [2][]uint64
+
+	// first line with a stale PreSum, len(vec) when all are valid
+	dirtyFrom int
+	// bits changed since the pre sums and select samples were built, also
+	// set by a change in the last line, which leaves all pre sums valid
+	changed bool
+
+	// goroutines used by the batch queries, 0 uses GOMAXPROCS
+	batchWorkers int
+}
+
+// Copy the bits back into a plain vector
+func (i *InterleavedVector) Vector() Vector {
+	vec := MakeVector(i.length)
+	subvectors := vec.Subvectors()
+
+	for j := range i.vec {
+		copy(subvectors[j*int(InterleavedSubvectorCount):], i.vec[j].Vec[:])
+	}
+
+	return vec
 }
 
 // Calculate the pre sums on an otherwise filled InterleavedVector
 func (i *InterleavedVector) Precompute() {
+	i.checkWritable()
+
+	linePopcounts(i.vec)
+
 	var sum uint64
 
 	for j := range len(i.vec) {
+		ones := i.vec[j].PreSum
 		i.vec[j].PreSum = sum
-		sum += Vector(i.vec[j].Vec[:]).Ones()
+		sum += ones
 	}
+
+	i.finishPrecompute(sum)
+}
+
+// Record the total after all pre sums are valid
+func (i *InterleavedVector) finishPrecompute(ones uint64) {
+	i.ones = ones
+	i.dirtyFrom = len(i.vec)
+	i.changed = false
+
+	if i.sampleRate > 0 {
+		i.buildSelectSamples()
+	}
+}
+
+// Number of alphas in the whole vector
+func (i *InterleavedVector) count(alpha bool) uint64 {
+	if alpha {
+		return i.ones
+	}
+	return i.length - i.ones
 }
 
 // Find the correct subvector and also get its inner position
@@ -94,20 +145,28 @@ func (i *InterleavedVector) GetSubvector(position uint64) (innerPos uint8, subve
 	return
 }
 
-// Set implements Setable.
-func (i *InterleavedVector) Set(position uint64) {
-	pos, sv := i.GetSubvector(position)
-	sv.Set(pos)
-}
-
 // Access implements RankSelectVector.
 func (i *InterleavedVector) Access(position uint64) bool {
+	i.checkPosition(position)
 	ipos, sv := i.GetSubvector(position)
 	return sv.Access(ipos)
 }
 
 // Rank implements RankSelectVector.(number before)
 func (i *InterleavedVector) Rank(alpha bool, position uint64) uint64 {
+	if err := i.checkFlushed(); err != nil {
+		panic(err)
+	}
+
+	if err := checkRank(position, i.length); err != nil {
+		panic(err)
+	}
+
+	// the position right after the last bit may not have a line
+	if position == i.length {
+		return i.count(alpha)
+	}
+
 	subvectorPos := position / SubvectorBits
 	innerSubVecPos := position % SubvectorBits
 
@@ -117,8 +176,9 @@ func (i *InterleavedVector) Rank(alpha bool, position uint64) uint64 {
 	line := i.vec[interleavedVectorLinePos]
 	rank := line.PreSum
 
+	// at most 6 subvectors, too few for the vectorized popcount
 	if interleavedSubVectorPos > 0 {
-		rank += Vector(line.Vec[0:(interleavedSubVectorPos)]).Ones()
+		rank += onesCountGo(line.Vec[0:(interleavedSubVectorPos)])
 	}
 
 	rank += uint64(line.Vec[interleavedSubVectorPos].Rank(true, uint8(innerSubVecPos)))
@@ -132,8 +192,17 @@ func (i *InterleavedVector) Rank(alpha bool, position uint64) uint64 {
 
 // Select implements RankSelectVector.(nth one)
 func (i *InterleavedVector) Select(alpha bool, n uint64) uint64 {
+	if err := i.checkFlushed(); err != nil {
+		panic(err)
+	}
 
-	linePos, _ := i.BinarySearch(alpha, n)
+	// also guarantees that the padding is never reached
+	if err := checkSelect(alpha, n, i.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	lo, hi := i.selectSearchRange(alpha, n)
+	linePos := i.binarySearchRange(alpha, n, lo, hi)
 
 	if linePos > 0 {
 		linePos--
@@ -172,12 +241,22 @@ func (i *InterleavedVector) Select(alpha bool, n uint64) uint64 {
 }
 
 func (c *InterleavedVector) BinarySearch(alpha bool, target uint64) (uint64, bool) {
+	if err := c.checkFlushed(); err != nil {
+		panic(err)
+	}
 
-	// Inlining is faster than calling BinarySearchFunc with a lambda.
 	n := uint64(len(c.vec))
-	// Define x[-1] < target and x[n] >= target.
+	i := c.binarySearchRange(alpha, target, 0, n)
+	return i, i < n && c.vec[i].PreSum == target
+}
+
+// Find the first line in [lo, hi) whose pre sum of alphas is at least target
+func (c *InterleavedVector) binarySearchRange(alpha bool, target, lo, hi uint64) uint64 {
+
+	// Inlining is faster than calling BinarySearchFunc with a lambda.
+	// Define x[lo-1] < target and x[hi] >= target.
 	// Invariant: x[i-1] < target, x[j] >= target.
-	i, j := uint64(0), n
+	i, j := lo, hi
 	for i < j {
 		h := uint64(uint(i+j) >> 1) // avoid overflow when computing h
 		// i ≤ h < j
@@ -194,17 +273,84 @@ func (c *InterleavedVector) BinarySearch(alpha bool, target uint64) (uint64, boo
 		}
 	}
 	// i == j, x[i-1] < target, and x[j] (= x[i]) >= target  =>  answer is i.
-	return i, i < n && c.vec[i].PreSum == target
+	return i
+}
+
+func (i *InterleavedVector) checkPosition(position uint64) {
+	if err := checkAccess(position, i.length); err != nil {
+		panic(err)
+	}
+}
+
+func (i *InterleavedVector) checkWritable() {
+	if i.readOnly {
+		panic(ErrReadOnly)
+	}
+}
+
+// TryAccess implements RankSelectVector.
+func (i *InterleavedVector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, i.length); err != nil {
+		return false, err
+	}
+	return i.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (i *InterleavedVector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := i.checkFlushed(); err != nil {
+		return 0, err
+	}
+	if err := checkRank(position, i.length); err != nil {
+		return 0, err
+	}
+	return i.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (i *InterleavedVector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := i.checkFlushed(); err != nil {
+		return 0, err
+	}
+	if err := checkSelect(alpha, n, i.count(alpha)); err != nil {
+		return 0, err
+	}
+	return i.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (i *InterleavedVector) RankRange(alpha bool, l, r uint64) uint64 {
+	return must(i.TryRankRange(alpha, l, r))
+}
+
+// SelectFrom implements RankSelectVector.
+func (i *InterleavedVector) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(i.TrySelectFrom(alpha, p, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (i *InterleavedVector) TryRankRange(alpha bool, l, r uint64) (uint64, error) {
+	return tryRankRange(i, alpha, l, r)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (i *InterleavedVector) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	return trySelectFrom(i, alpha, p, k)
+}
+
+// Logical number of bits
+func (i *InterleavedVector) Bits() uint64 {
+	return i.length
 }
 
 // Overhead implements RankSelectVector.
 func (i *InterleavedVector) Overhead() uint64 {
-	// 1 uint64 per line
-	return uint64(len(i.vec) * 64)
+	// 1 uint64 per line, the padding of the last line and the select samples
+	return i.Size() - i.length
 }
 
 // Size implements RankSelectVector.
 func (i *InterleavedVector) Size() uint64 {
 	// each line is 512 bit in size
-	return uint64(len(i.vec) * 512)
+	return uint64(len(i.vec)*512) + i.selectSamplesSize()
 }
@@ -0,0 +1,128 @@
+package bit
+
+import "fmt"
+
+// Batch queries answer many queries at once, split over several goroutines.
+// Only the serial path is free of allocations: batches below 2*batchMinChunk
+// queries, or any batch with a single worker, run on the calling goroutine.
+// Larger batches with more workers allocate the goroutines, their closures
+// and a WaitGroup on every call. The results are written to out.
+
+// Below this many queries per goroutine a batch is not split further
+const batchMinChunk = 4096
+
+// Number of goroutines used by the batch queries, 0 uses GOMAXPROCS.
+// With 1 the batches run on the calling goroutine and do not allocate.
+func (i *InterleavedVector) SetBatchWorkers(workers int) {
+	i.batchWorkers = workers
+}
+
+func checkBatchLength(in, out int) error {
+	if in != out {
+		return fmt.Errorf("%w: %d queries but %d results", ErrLengthMismatch, in, out)
+	}
+	return nil
+}
+
+// Whether a batch of n queries runs on the calling goroutine
+func (i *InterleavedVector) batchSerial(n int) bool {
+	return n < 2*batchMinChunk || defaultWorkers(i.batchWorkers) == 1
+}
+
+// Rank of every position, written to out.
+// All positions are validated before any query runs. Only a serial batch
+// does not allocate, see the top of this file.
+func (i *InterleavedVector) RankBatch(alpha bool, positions []uint64, out []uint64) error {
+	if err := checkBatchLength(len(positions), len(out)); err != nil {
+		return err
+	}
+	if err := i.checkFlushed(); err != nil {
+		return err
+	}
+	for k, position := range positions {
+		if err := checkRank(position, i.length); err != nil {
+			return fmt.Errorf("query %d: %w", k, err)
+		}
+	}
+
+	if i.batchSerial(len(positions)) {
+		i.rankBatch(alpha, positions, out)
+		return nil
+	}
+
+	parallelChunks(len(positions), i.batchWorkers, batchMinChunk, func(start, end int) {
+		i.rankBatch(alpha, positions[start:end], out[start:end])
+	})
+	return nil
+}
+
+func (i *InterleavedVector) rankBatch(alpha bool, positions []uint64, out []uint64) {
+	for k, position := range positions {
+		out[k] = i.Rank(alpha, position)
+	}
+}
+
+// Position of the n'th alpha for every n, written to out.
+// All occurrences are validated before any query runs. Only a serial batch
+// does not allocate, see the top of this file.
+func (i *InterleavedVector) SelectBatch(alpha bool, ns []uint64, out []uint64) error {
+	if err := checkBatchLength(len(ns), len(out)); err != nil {
+		return err
+	}
+	if err := i.checkFlushed(); err != nil {
+		return err
+	}
+
+	count := i.count(alpha)
+	for k, n := range ns {
+		if err := checkSelect(alpha, n, count); err != nil {
+			return fmt.Errorf("query %d: %w", k, err)
+		}
+	}
+
+	if i.batchSerial(len(ns)) {
+		i.selectBatch(alpha, ns, out)
+		return nil
+	}
+
+	parallelChunks(len(ns), i.batchWorkers, batchMinChunk, func(start, end int) {
+		i.selectBatch(alpha, ns[start:end], out[start:end])
+	})
+	return nil
+}
+
+func (i *InterleavedVector) selectBatch(alpha bool, ns []uint64, out []uint64) {
+	for k, n := range ns {
+		out[k] = i.Select(alpha, n)
+	}
+}
+
+// Bit at every position, written to out.
+// All positions are validated before any query runs. Only a serial batch
+// does not allocate, see the top of this file.
+func (i *InterleavedVector) AccessBatch(positions []uint64, out []bool) error {
+	if err := checkBatchLength(len(positions), len(out)); err != nil {
+		return err
+	}
+	for k, position := range positions {
+		if err := checkAccess(position, i.length); err != nil {
+			return fmt.Errorf("query %d: %w", k, err)
+		}
+	}
+
+	if i.batchSerial(len(positions)) {
+		i.accessBatch(positions, out)
+		return nil
+	}
+
+	parallelChunks(len(positions), i.batchWorkers, batchMinChunk, func(start, end int) {
+		i.accessBatch(positions[start:end], out[start:end])
+	})
+	return nil
+}
+
+func (i *InterleavedVector) accessBatch(positions []uint64, out []bool) {
+	for k, position := range positions {
+		out[k] = i.Access(position)
+	}
+}
@@ -0,0 +1,117 @@
+package bit_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleavedBatchQueries(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(randomVector(50_000))
+	ones := interleaved.Rank(true, interleaved.Bits())
+
+	const queries = 100_000
+	positions := make([]uint64, queries)
+	ns := make([]uint64, queries)
+	for k := range positions {
+		positions[k] = uint64(rand.Int63n(int64(interleaved.Bits())))
+		ns[k] = uint64(rand.Int63n(int64(ones))) + 1
+	}
+
+	for _, workers := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("workers %d", workers), func(t *testing.T) {
+			interleaved.SetBatchWorkers(workers)
+
+			ranks := make([]uint64, queries)
+			selects := make([]uint64, queries)
+			access := make([]bool, queries)
+
+			assert.NoError(t, interleaved.RankBatch(false, positions, ranks))
+			assert.NoError(t, interleaved.SelectBatch(true, ns, selects))
+			assert.NoError(t, interleaved.AccessBatch(positions, access))
+
+			for k := range positions {
+				assert.Equal(t, interleaved.Rank(false, positions[k]), ranks[k])
+				assert.Equal(t, interleaved.Select(true, ns[k]), selects[k])
+				assert.Equal(t, interleaved.Access(positions[k]), access[k])
+			}
+		})
+	}
+}
+
+func TestInterleavedBatchErrors(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(bit.NewVector("0110"))
+	out := make([]uint64, 2)
+
+	assert.ErrorIs(t, interleaved.RankBatch(true, []uint64{1, 5}, out), bit.ErrOutOfRange)
+	assert.ErrorIs(t, interleaved.SelectBatch(true, []uint64{1, 3}, out), bit.ErrNotEnoughOccurrences)
+	assert.ErrorIs(t, interleaved.AccessBatch([]uint64{4, 0}, make([]bool, 2)), bit.ErrOutOfRange)
+	assert.ErrorIs(t, interleaved.RankBatch(true, []uint64{1}, out), bit.ErrLengthMismatch)
+}
+
+func TestInterleavedSmallBatchDoesNotAllocate(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(randomVector(100_000))
+
+	positions := make([]uint64, 1000)
+	for k := range positions {
+		positions[k] = uint64(rand.Int63n(int64(interleaved.Bits())))
+	}
+	out := make([]uint64, len(positions))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		interleaved.RankBatch(true, positions, out)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestInterleavedSingleWorkerBatchDoesNotAllocate(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(randomVector(100_000))
+	ones := interleaved.Rank(true, interleaved.Bits())
+
+	// large enough to be split with more workers
+	positions := make([]uint64, 20_000)
+	ns := make([]uint64, len(positions))
+	for k := range positions {
+		positions[k] = uint64(rand.Int63n(int64(interleaved.Bits())))
+		ns[k] = uint64(rand.Int63n(int64(ones))) + 1
+	}
+	out := make([]uint64, len(positions))
+	access := make([]bool, len(positions))
+
+	interleaved.SetBatchWorkers(1)
+	allocs := testing.AllocsPerRun(10, func() {
+		interleaved.RankBatch(true, positions, out)
+		interleaved.SelectBatch(true, ns, out)
+		interleaved.AccessBatch(positions, access)
+	})
+	assert.Zero(t, allocs)
+
+	// the parallel path is not covered by the guarantee
+	interleaved.SetBatchWorkers(4)
+	allocs = testing.AllocsPerRun(10, func() {
+		interleaved.RankBatch(true, positions, out)
+	})
+	assert.NotZero(t, allocs)
+}
+
+func BenchmarkRankBatch(b *testing.B) {
+	interleaved := bit.NewInterleavedVector(randomVector(8388608))
+
+	positions := make([]uint64, 1_000_000)
+	for k := range positions {
+		positions[k] = uint64(rand.Int63n(int64(interleaved.Bits())))
+	}
+	out := make([]uint64, len(positions))
+
+	for _, workers := range []int{1, 0} {
+		b.Run(fmt.Sprintf("workers %d", workers), func(b *testing.B) {
+			interleaved.SetBatchWorkers(workers)
+			for i := 0; i < b.N; i++ {
+				interleaved.RankBatch(true, positions, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package bit
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// On-disk format of an InterleavedVector, all integers are little-endian.
+//
+//	offset  size       field
+//	0       4          magic "IVEC"
+//	4       2          format version
+//	6       2          reserved, zero
+//	8       8          logical length in bits
+//	16      8          total number of ones
+//	24      8          number of lines
+//	32      32         reserved, zero (pads the header to one cache line)
+//	64      64*lines   lines, each PreSum followed by the 7 subvectors
+//	...     4          CRC-32 (Castagnoli) of everything before
+//
+// The header keeps the lines 64 byte aligned relative to the file start,
+// so a mapping of the file can use them without copying.
+const (
+	EncodingMagic   = "IVEC"
+	EncodingVersion = 1
+
+	encodingHeaderSize  = 64
+	encodingLineSize    = 64
+	encodingTrailerSize = 4
+)
+
+var (
+	ErrInvalidFormat      = errors.New("invalid interleaved vector format")
+	ErrUnsupportedVersion = errors.New("unsupported interleaved vector format version")
+	ErrChecksumMismatch   = errors.New("interleaved vector checksum mismatch")
+)
+
+var _ encoding.BinaryMarshaler = (*InterleavedVector)(nil)
+var _ encoding.BinaryUnmarshaler = (*InterleavedVector)(nil)
+var _ io.WriterTo = (*InterleavedVector)(nil)
+var _ io.ReaderFrom = (*InterleavedVector)(nil)
+
+var encodingTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Lines are en- and decoded in chunks of this many lines
+const encodingChunkLines = 1024
+
+type encodingHeader struct {
+	version uint16
+	length  uint64
+	ones    uint64
+	lines   uint64
+}
+
+func (h encodingHeader) encode() []byte {
+	buf := make([]byte, encodingHeaderSize)
+	copy(buf[0:4], EncodingMagic)
+	binary.LittleEndian.PutUint16(buf[4:6], h.version)
+	binary.LittleEndian.PutUint64(buf[8:16], h.length)
+	binary.LittleEndian.PutUint64(buf[16:24], h.ones)
+	binary.LittleEndian.PutUint64(buf[24:32], h.lines)
+	return buf
+}
+
+// Bytes of an encoding with the given number of lines
+func encodedSize(lines uint64) uint64 {
+	return encodingHeaderSize + lines*encodingLineSize + encodingTrailerSize
+}
+
+// The header is not trusted, nothing may be allocated from it before the
+// data it describes has been read
+func decodeEncodingHeader(buf []byte) (encodingHeader, error) {
+	if len(buf) < encodingHeaderSize || string(buf[0:4]) != EncodingMagic {
+		return encodingHeader{}, fmt.Errorf("%w: bad magic", ErrInvalidFormat)
+	}
+
+	h := encodingHeader{
+		version: binary.LittleEndian.Uint16(buf[4:6]),
+		length:  binary.LittleEndian.Uint64(buf[8:16]),
+		ones:    binary.LittleEndian.Uint64(buf[16:24]),
+		lines:   binary.LittleEndian.Uint64(buf[24:32]),
+	}
+
+	if h.version != EncodingVersion {
+		return h, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.version)
+	}
+
+	// larger lengths overflow the number of subvectors
+	if h.length > math.MaxUint64-(SubvectorBits-1) {
+		return h, fmt.Errorf("%w: length %d", ErrInvalidFormat, h.length)
+	}
+
+	expectedLines := (subvectorCount(h.length) + InterleavedSubvectorCount - 1) / InterleavedSubvectorCount
+	if h.lines != expectedLines || h.ones > h.length {
+		return h, fmt.Errorf("%w: header with %d lines for %d bits and %d ones", ErrInvalidFormat, h.lines, h.length, h.ones)
+	}
+
+	return h, nil
+}
+
+// WriteTo implements io.WriterTo.
+func (i *InterleavedVector) WriteTo(w io.Writer) (int64, error) {
+	if err := i.checkFlushed(); err != nil {
+		return 0, err
+	}
+
+	crc := crc32.New(encodingTable)
+	out := io.MultiWriter(w, crc)
+
+	var written int64
+
+	header := encodingHeader{
+		version: EncodingVersion,
+		length:  i.length,
+		ones:    i.ones,
+		lines:   uint64(len(i.vec)),
+	}
+	n, err := out.Write(header.encode())
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	buf := make([]byte, 0, encodingChunkLines*encodingLineSize)
+	for start := 0; start < len(i.vec); start += encodingChunkLines {
+		end := min(start+encodingChunkLines, len(i.vec))
+
+		buf = buf[:0]
+		for _, line := range i.vec[start:end] {
+			buf = binary.LittleEndian.AppendUint64(buf, line.PreSum)
+			for _, sv := range line.Vec {
+				buf = binary.LittleEndian.AppendUint64(buf, uint64(sv))
+			}
+		}
+
+		n, err := out.Write(buf)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	n, err = w.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32()))
+	written += int64(n)
+	return written, err
+}
+
+// ReadFrom implements io.ReaderFrom.
+// The vector is replaced by the decoded one, no Precompute is needed.
+func (i *InterleavedVector) ReadFrom(r io.Reader) (int64, error) {
+	crc := crc32.New(encodingTable)
+	var read int64
+
+	headerBuf := make([]byte, encodingHeaderSize)
+	if err := readChecked(r, crc, headerBuf, &read); err != nil {
+		return read, err
+	}
+
+	header, err := decodeEncodingHeader(headerBuf)
+	if err != nil {
+		return read, err
+	}
+
+	// the lines grow with the data read, a forged line count fails as
+	// truncated instead of allocating up front
+	vec := make([]InterleavedVectorLine, 0, min(header.lines, encodingChunkLines))
+
+	buf := make([]byte, encodingChunkLines*encodingLineSize)
+	for remaining := header.lines; remaining > 0; {
+		lines := min(remaining, encodingChunkLines)
+		remaining -= lines
+
+		chunk := buf[:lines*encodingLineSize]
+		if err := readChecked(r, crc, chunk, &read); err != nil {
+			return read, err
+		}
+
+		for j := range lines {
+			var line InterleavedVectorLine
+			decodeLine(chunk[j*encodingLineSize:], &line)
+			vec = append(vec, line)
+		}
+	}
+
+	trailer := make([]byte, encodingTrailerSize)
+	n, err := io.ReadFull(r, trailer)
+	read += int64(n)
+	if err != nil {
+		return read, truncated(err)
+	}
+
+	if binary.LittleEndian.Uint32(trailer) != crc.Sum32() {
+		return read, ErrChecksumMismatch
+	}
+
+	i.vec = vec
+	i.length = header.length
+	i.ones = header.ones
+	i.dirtyFrom = len(vec)
+	i.changed = false
+	i.selectSamples = [2][]uint64{}
+	if i.sampleRate > 0 {
+		i.buildSelectSamples()
+	}
+
+	return read, nil
+}
+
+func decodeLine(buf []byte, line *InterleavedVectorLine) {
+	line.PreSum = binary.LittleEndian.Uint64(buf)
+	for k := range line.Vec {
+		line.Vec[k] = Subvector(binary.LittleEndian.Uint64(buf[8*(k+1):]))
+	}
+}
+
+// Fill buf completely and feed it into the checksum
+func readChecked(r io.Reader, crc hash.Hash32, buf []byte, read *int64) error {
+	n, err := io.ReadFull(r, buf)
+	*read += int64(n)
+	if err != nil {
+		return truncated(err)
+	}
+	crc.Write(buf)
+	return nil
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated", ErrInvalidFormat)
+	}
+	return err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (i *InterleavedVector) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(encodingHeaderSize + len(i.vec)*encodingLineSize + encodingTrailerSize)
+
+	if _, err := i.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (i *InterleavedVector) UnmarshalBinary(data []byte) error {
+	header, err := decodeEncodingHeader(data)
+	if err != nil {
+		return err
+	}
+
+	if expectedSize := encodedSize(header.lines); uint64(len(data)) != expectedSize {
+		return fmt.Errorf("%w: %d bytes, expected %d", ErrInvalidFormat, len(data), expectedSize)
+	}
+
+	_, err = i.ReadFrom(bytes.NewReader(data))
+	return err
+}
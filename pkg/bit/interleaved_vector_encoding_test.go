@@ -0,0 +1,144 @@
+package bit_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleavedEncodingRoundTrip(t *testing.T) {
+	vec := randomVector(2000)
+	original := bit.NewInterleavedVector(bit.NewVectorFromSubvectors(vec.Subvectors(), vec.Bits()-13))
+
+	data, err := original.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, 64+int(original.Size()/8)+4, len(data))
+
+	var decoded bit.InterleavedVector
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+
+	assert.Equal(t, original.Bits(), decoded.Bits())
+	assert.Equal(t, original.Size(), decoded.Size())
+
+	ones := original.Rank(true, original.Bits())
+	for i := uint64(1); i < ones; i += 997 {
+		assert.Equal(t, original.Select(true, i), decoded.Select(true, i))
+	}
+	for pos := uint64(0); pos <= original.Bits(); pos += 1009 {
+		assert.Equal(t, original.Rank(true, pos), decoded.Rank(true, pos))
+	}
+}
+
+func TestInterleavedWriteToReadFrom(t *testing.T) {
+	original := bit.NewInterleavedVector(bit.NewVector("0110111"))
+
+	var buf bytes.Buffer
+	written, err := original.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+
+	var decoded bit.InterleavedVector
+	read, err := decoded.ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, written, read)
+	assert.Equal(t, uint64(5), decoded.Rank(true, 7))
+}
+
+func TestInterleavedDecodingErrors(t *testing.T) {
+	valid, err := bit.NewInterleavedVector(bit.NewVector("0110111")).MarshalBinary()
+	assert.NoError(t, err)
+
+	corrupt := func(f func(data []byte) []byte) []byte {
+		return f(bytes.Clone(valid))
+	}
+
+	testCases := []struct {
+		desc     string
+		data     []byte
+		expected error
+	}{
+		{
+			desc:     "bad magic",
+			data:     corrupt(func(d []byte) []byte { d[0] = 'X'; return d }),
+			expected: bit.ErrInvalidFormat,
+		},
+		{
+			desc: "unknown version",
+			data: corrupt(func(d []byte) []byte {
+				binary.LittleEndian.PutUint16(d[4:], 99)
+				return d
+			}),
+			expected: bit.ErrUnsupportedVersion,
+		},
+		{
+			desc:     "flipped data bit",
+			data:     corrupt(func(d []byte) []byte { d[80] ^= 1; return d }),
+			expected: bit.ErrChecksumMismatch,
+		},
+		{
+			desc:     "truncated",
+			data:     valid[:len(valid)-10],
+			expected: bit.ErrInvalidFormat,
+		},
+		{
+			desc:     "trailing bytes",
+			data:     append(bytes.Clone(valid), 0),
+			expected: bit.ErrInvalidFormat,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var decoded bit.InterleavedVector
+			assert.ErrorIs(t, decoded.UnmarshalBinary(tC.data), tC.expected)
+		})
+	}
+}
+
+// A header alone, claiming length bits in lines lines
+func craftedHeader(length, lines uint64) []byte {
+	header := make([]byte, 64)
+	copy(header, bit.EncodingMagic)
+	binary.LittleEndian.PutUint16(header[4:], bit.EncodingVersion)
+	binary.LittleEndian.PutUint64(header[8:], length)
+	binary.LittleEndian.PutUint64(header[24:], lines)
+	return header
+}
+
+func TestInterleavedDecodingCraftedHeader(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		length uint64
+		lines  uint64
+	}{
+		{
+			desc:   "huge length",
+			length: 1 << 62,
+			lines:  (1<<62/64 + 6) / 7,
+		},
+		{
+			desc:   "line count overflows",
+			length: math.MaxUint64,
+			lines:  0,
+		},
+		{
+			desc:   "length near overflow",
+			length: math.MaxUint64 - 10,
+			lines:  0,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			data := craftedHeader(tC.length, tC.lines)
+
+			var decoded bit.InterleavedVector
+			assert.ErrorIs(t, decoded.UnmarshalBinary(data), bit.ErrInvalidFormat)
+
+			_, err := decoded.ReadFrom(bytes.NewReader(data))
+			assert.ErrorIs(t, err, bit.ErrInvalidFormat)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package bit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"unsafe"
+)
+
+var (
+	ErrReadOnly        = errors.New("interleaved vector is read-only")
+	ErrClosed          = errors.New("mapped interleaved vector is closed")
+	ErrMmapUnsupported = errors.New("memory mapping is not supported on this platform")
+)
+
+// An InterleavedVector whose lines are used directly from a read-only
+// mapping of a file written by WriteTo. Several processes mapping the same
+// file share the page cache instead of each holding a copy on the heap.
+// The vector must not be used after Close.
+type MappedInterleavedVector struct {
+	*InterleavedVector
+
+	data   []byte
+	unmap  func([]byte) error
+	closed bool
+}
+
+// Interpret an encoded vector without copying the lines.
+// Only the header is validated, use Verify to check the checksum.
+func newMappedInterleavedVector(data []byte, unmap func([]byte) error) (*MappedInterleavedVector, error) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		return nil, fmt.Errorf("%w: lines can only be shared on little-endian hosts", ErrMmapUnsupported)
+	}
+
+	header, err := decodeEncodingHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedSize := encodedSize(header.lines)
+	if uint64(len(data)) != expectedSize {
+		return nil, fmt.Errorf("%w: file has %d bytes, expected %d", ErrInvalidFormat, len(data), expectedSize)
+	}
+
+	var lines []InterleavedVectorLine
+	if header.lines > 0 {
+		first := unsafe.Pointer(&data[encodingHeaderSize])
+		if uintptr(first)%unsafe.Alignof(InterleavedVectorLine{}) != 0 {
+			return nil, fmt.Errorf("%w: lines are not aligned", ErrInvalidFormat)
+		}
+		lines = unsafe.Slice((*InterleavedVectorLine)(first), header.lines)
+	}
+
+	return &MappedInterleavedVector{
+		InterleavedVector: &InterleavedVector{
+			vec:       lines,
+			length:    header.length,
+			ones:      header.ones,
+			readOnly:  true,
+			dirtyFrom: len(lines),
+		},
+		data:  data,
+		unmap: unmap,
+	}, nil
+}
+
+// Check the checksum of the mapped file, this reads every page once
+func (m *MappedInterleavedVector) Verify() error {
+	if m.closed {
+		return ErrClosed
+	}
+
+	payload := m.data[:len(m.data)-encodingTrailerSize]
+	trailer := m.data[len(m.data)-encodingTrailerSize:]
+
+	if crc32.Checksum(payload, encodingTable) != binary.LittleEndian.Uint32(trailer) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Release the mapping. Calling Close more than once is a no-op.
+func (m *MappedInterleavedVector) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	// drop the references into the mapping, the vector is empty afterwards,
+	// so the Try queries report ErrOutOfRange instead of touching it
+	*m.InterleavedVector = InterleavedVector{readOnly: true}
+	data := m.data
+	m.data = nil
+
+	return m.unmap(data)
+}
@@ -0,0 +1,40 @@
+package bit
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Map a file written by InterleavedVector.WriteTo into memory, read-only.
+func OpenMappedInterleavedVector(path string) (*MappedInterleavedVector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// the mapping stays valid after the file is closed
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	if size < encodingHeaderSize+encodingTrailerSize {
+		return nil, fmt.Errorf("%w: file too small", ErrInvalidFormat)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("could not map %s: %w", path, err)
+	}
+
+	vec, err := newMappedInterleavedVector(data, syscall.Munmap)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+
+	return vec, nil
+}
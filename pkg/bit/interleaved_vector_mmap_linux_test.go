@@ -0,0 +1,86 @@
+package bit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeInterleaved(t *testing.T, vec *bit.InterleavedVector) string {
+	path := filepath.Join(t.TempDir(), "vector.ivec")
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = vec.WriteTo(f)
+	require.NoError(t, err)
+
+	return path
+}
+
+func TestMappedInterleavedVector(t *testing.T) {
+	vec := randomVector(5000)
+	original := bit.NewInterleavedVector(vec)
+
+	mapped, err := bit.OpenMappedInterleavedVector(writeInterleaved(t, original))
+	require.NoError(t, err)
+	defer mapped.Close()
+
+	assert.NoError(t, mapped.Verify())
+	assert.Equal(t, original.Bits(), mapped.Bits())
+
+	for pos := uint64(0); pos < original.Bits(); pos += 4999 {
+		assert.Equal(t, original.Access(pos), mapped.Access(pos))
+		assert.Equal(t, original.Rank(true, pos), mapped.Rank(true, pos))
+	}
+	for n := uint64(1); n < original.Rank(false, original.Bits()); n += 3001 {
+		assert.Equal(t, original.Select(false, n), mapped.Select(false, n))
+	}
+
+	assert.PanicsWithValue(t, bit.ErrReadOnly, func() {
+		mapped.Set(0)
+	})
+
+	assert.NoError(t, mapped.Close())
+	assert.NoError(t, mapped.Close())
+	assert.ErrorIs(t, mapped.Verify(), bit.ErrClosed)
+
+	// queries after Close fail without touching the mapping
+	assert.NotPanics(t, func() {
+		_, err := mapped.TryAccess(0)
+		assert.ErrorIs(t, err, bit.ErrOutOfRange)
+		_, err = mapped.TryRank(true, 1)
+		assert.ErrorIs(t, err, bit.ErrOutOfRange)
+		_, err = mapped.TrySelect(true, 1)
+		assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+	})
+	assert.Panics(t, func() { mapped.Access(0) })
+}
+
+func TestMappedInterleavedVectorInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "garbage")
+	require.NoError(t, os.WriteFile(path, make([]byte, 200), 0666))
+
+	_, err := bit.OpenMappedInterleavedVector(path)
+	assert.ErrorIs(t, err, bit.ErrInvalidFormat)
+}
+
+func TestMappedInterleavedVectorChecksum(t *testing.T) {
+	path := writeInterleaved(t, bit.NewInterleavedVector(bit.NewVector("0110111")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[70] ^= 0xFF
+	require.NoError(t, os.WriteFile(path, data, 0666))
+
+	mapped, err := bit.OpenMappedInterleavedVector(path)
+	require.NoError(t, err)
+	defer mapped.Close()
+
+	assert.ErrorIs(t, mapped.Verify(), bit.ErrChecksumMismatch)
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package bit
+
+// Map a file written by InterleavedVector.WriteTo into memory, read-only.
+func OpenMappedInterleavedVector(path string) (*MappedInterleavedVector, error) {
+	return nil, ErrMmapUnsupported
+}
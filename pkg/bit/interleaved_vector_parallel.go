@@ -0,0 +1,57 @@
+package bit
+
+// Below this many lines per goroutine the build is not split further
+const precomputeMinChunk = 16384
+
+// Like NewInterleavedVector, but copies the lines and computes the pre sums
+// with up to workers goroutines, 0 uses GOMAXPROCS.
+func NewInterleavedVectorParallel(vec Vector, workers int) *InterleavedVector {
+	intlVec := newInterleavedVectorLines(vec)
+
+	parallelChunks(len(intlVec.vec), workers, precomputeMinChunk, func(start, end int) {
+		intlVec.copyLines(vec.Subvectors(), start, end)
+	})
+
+	intlVec.PrecomputeParallel(workers)
+	return intlVec
+}
+
+// Like Precompute, but with up to workers goroutines, 0 uses GOMAXPROCS.
+// Every goroutine counts the ones of its chunk of lines, then the chunk
+// totals are summed up and every goroutine turns its counts into pre sums.
+// The result is identical to Precompute.
+func (i *InterleavedVector) PrecomputeParallel(workers int) {
+	i.checkWritable()
+
+	bounds := splitChunks(len(i.vec), workers, precomputeMinChunk)
+	totals := make([]uint64, len(bounds)-1)
+
+	// the pre sums hold the ones of their own line in between
+	runChunks(bounds, func(chunk, start, end int) {
+		linePopcounts(i.vec[start:end])
+
+		var sum uint64
+		for j := start; j < end; j++ {
+			sum += i.vec[j].PreSum
+		}
+		totals[chunk] = sum
+	})
+
+	// exclusive prefix sum of the chunk totals
+	var sum uint64
+	for chunk, total := range totals {
+		totals[chunk] = sum
+		sum += total
+	}
+
+	runChunks(bounds, func(chunk, start, end int) {
+		preSum := totals[chunk]
+		for j := start; j < end; j++ {
+			ones := i.vec[j].PreSum
+			i.vec[j].PreSum = preSum
+			preSum += ones
+		}
+	})
+
+	i.finishPrecompute(sum)
+}
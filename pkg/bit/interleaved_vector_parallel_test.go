@@ -0,0 +1,49 @@
+package bit_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelPrecomputeIdenticalToSequential(t *testing.T) {
+	for _, size := range []uint64{0, 1, 1000, 500_000} {
+		vec := randomVector(size)
+		vec = bit.NewVectorFromSubvectors(vec.Subvectors(), vec.Bits()-min(vec.Bits(), 5))
+
+		sequential, err := bit.NewInterleavedVector(vec).MarshalBinary()
+		assert.NoError(t, err)
+
+		for _, workers := range []int{0, 1, 2, 7} {
+			t.Run(fmt.Sprintf("%d subvectors, %d workers", size, workers), func(t *testing.T) {
+				parallel, err := bit.NewInterleavedVectorParallel(vec, workers).MarshalBinary()
+				assert.NoError(t, err)
+				assert.Equal(t, sequential, parallel)
+
+				interleaved := bit.NewInterleavedVectorNoPrecompute(vec)
+				interleaved.PrecomputeParallel(workers)
+				parallel, err = interleaved.MarshalBinary()
+				assert.NoError(t, err)
+				assert.Equal(t, sequential, parallel)
+			})
+		}
+	}
+}
+
+func BenchmarkPrecompute(b *testing.B) {
+	vec := randomVector(1 << 24)
+	interleaved := bit.NewInterleavedVectorNoPrecompute(vec)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			interleaved.Precompute()
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			interleaved.PrecomputeParallel(0)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package bit_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectSamplesVsBinarySearch(t *testing.T) {
+	vector := randomVector(20_000)
+	// sparse region to get samples spanning many lines
+	for i := range vector.Subvectors()[5000:9000] {
+		vector.Subvectors()[5000+i] &= 1
+	}
+
+	plain := bit.NewInterleavedVector(vector)
+
+	for _, rate := range []uint64{1, 7, 64, bit.DefaultSelectSampleRate} {
+		t.Run(fmt.Sprintf("rate %d", rate), func(t *testing.T) {
+			sampled := bit.NewInterleavedVector(vector)
+			sampled.SetSelectSampleRate(rate)
+
+			assert.Greater(t, sampled.Overhead(), plain.Overhead())
+
+			for _, alpha := range []bool{true, false} {
+				count := plain.Rank(alpha, plain.Bits())
+
+				for _, n := range []uint64{1, count, rate, rate + 1} {
+					if n >= 1 && n <= count {
+						assert.Equal(t, plain.Select(alpha, n), sampled.Select(alpha, n), n)
+					}
+				}
+
+				for i := 0; i < 2000; i++ {
+					n := uint64(rand.Int63n(int64(count))) + 1
+					assert.Equal(t, plain.Select(alpha, n), sampled.Select(alpha, n), n)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectSamplesDisable(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(randomVector(100))
+	plainOverhead := interleaved.Overhead()
+
+	interleaved.SetSelectSampleRate(16)
+	assert.Equal(t, uint64(16), interleaved.SelectSampleRate())
+	assert.Greater(t, interleaved.Overhead(), plainOverhead)
+
+	interleaved.SetSelectSampleRate(0)
+	assert.Equal(t, plainOverhead, interleaved.Overhead())
+}
@@ -0,0 +1,126 @@
+package bit
+
+import "math/bits"
+
+// Successor queries first scan the line of the position, which is a single
+// cache line. Only if the alpha is not in there, the pre sums give the number
+// of alphas before the neighbouring line and a Select finds the answer.
+
+var _ Successor = (*InterleavedVector)(nil)
+
+// NextOne implements Successor.
+func (i *InterleavedVector) NextOne(position uint64) (uint64, bool) {
+	return i.next(true, position)
+}
+
+// PrevOne implements Successor.
+func (i *InterleavedVector) PrevOne(position uint64) (uint64, bool) {
+	return i.prev(true, position)
+}
+
+// NextZero implements Successor.
+func (i *InterleavedVector) NextZero(position uint64) (uint64, bool) {
+	return i.next(false, position)
+}
+
+// PrevZero implements Successor.
+func (i *InterleavedVector) PrevZero(position uint64) (uint64, bool) {
+	return i.prev(false, position)
+}
+
+// Subvector k of line with the alphas as ones, without the padding
+func (i *InterleavedVector) alphaWord(alpha bool, line, k uint64) uint64 {
+	word := uint64(i.vec[line].Vec[k])
+	if alpha {
+		return word
+	}
+
+	word = ^word
+	start := (line*InterleavedSubvectorCount + k) * SubvectorBits
+	if start+SubvectorBits > i.length {
+		word &= ^(uint64(SubvectorMax) << (i.length - start))
+	}
+	return word
+}
+
+// Number of alphas before line
+func (i *InterleavedVector) linePreSum(alpha bool, line uint64) uint64 {
+	if line == uint64(len(i.vec)) {
+		return i.count(alpha)
+	}
+
+	preSum := i.vec[line].PreSum
+	if !alpha {
+		return line*InterleavedSubvectorCount*SubvectorBits - preSum
+	}
+	return preSum
+}
+
+func (i *InterleavedVector) next(alpha bool, position uint64) (uint64, bool) {
+	if position >= i.length {
+		return 0, false
+	}
+	if err := i.checkFlushed(); err != nil {
+		panic(err)
+	}
+
+	subvectorPos := position / SubvectorBits
+	line := subvectorPos / InterleavedSubvectorCount
+	k := subvectorPos % InterleavedSubvectorCount
+
+	// the rest of the line, starting at position
+	word := i.alphaWord(alpha, line, k) & (uint64(SubvectorMax) << (position % SubvectorBits))
+	for {
+		if word != 0 {
+			start := (line*InterleavedSubvectorCount + k) * SubvectorBits
+			return start + uint64(bits.TrailingZeros64(word)), true
+		}
+
+		k++
+		if k == InterleavedSubvectorCount || (line*InterleavedSubvectorCount+k)*SubvectorBits >= i.length {
+			break
+		}
+		word = i.alphaWord(alpha, line, k)
+	}
+
+	before := i.linePreSum(alpha, line+1)
+	if before == i.count(alpha) {
+		return 0, false
+	}
+	return i.Select(alpha, before+1), true
+}
+
+func (i *InterleavedVector) prev(alpha bool, position uint64) (uint64, bool) {
+	if i.length == 0 {
+		return 0, false
+	}
+	position = min(position, i.length-1)
+	if err := i.checkFlushed(); err != nil {
+		panic(err)
+	}
+
+	subvectorPos := position / SubvectorBits
+	line := subvectorPos / InterleavedSubvectorCount
+	k := subvectorPos % InterleavedSubvectorCount
+
+	// the start of the line, up to and including position
+	word := i.alphaWord(alpha, line, k) & (uint64(SubvectorMax) >> (SubvectorBits - 1 - position%SubvectorBits))
+	for {
+		if word != 0 {
+			start := (line*InterleavedSubvectorCount + k) * SubvectorBits
+			return start + uint64(bits.Len64(word)) - 1, true
+		}
+
+		if k == 0 {
+			break
+		}
+		k--
+		word = i.alphaWord(alpha, line, k)
+	}
+
+	before := i.linePreSum(alpha, line)
+	if before == 0 {
+		return 0, false
+	}
+	return i.Select(alpha, before), true
+}
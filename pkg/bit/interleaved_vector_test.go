@@ -9,7 +9,7 @@ import (
 
 func TestInterleavedRank(t *testing.T) {
 
-	onesVec := bit.Vector{
+	onesVec := bit.NewVectorFromSubvectors([]bit.Subvector{
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 64
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 128
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 192
@@ -19,7 +19,7 @@ func TestInterleavedRank(t *testing.T) {
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 448
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 512
 		bit.Subvector(0xFFFFFFFF_FFFFFFFF), // 576
-	}
+	}, 576)
 
 	testCases := []struct {
 		desc     string
@@ -52,3 +52,64 @@ func TestInterleavedRank(t *testing.T) {
 		})
 	}
 }
+
+func TestInterleavedLogicalLength(t *testing.T) {
+	// 100 bits, all zero, padded to one 512 bit line
+	vec := bit.MakeVector(100)
+	vec.Set(3)
+
+	interleaved := bit.NewInterleavedVector(vec)
+
+	assert.Equal(t, uint64(100), interleaved.Bits())
+	assert.Equal(t, uint64(512), interleaved.Size())
+	assert.Equal(t, uint64(412), interleaved.Overhead())
+
+	assert.Equal(t, uint64(1), interleaved.Rank(true, 100))
+	assert.Equal(t, uint64(99), interleaved.Rank(false, 100))
+	assert.Equal(t, uint64(99), interleaved.Select(false, 99))
+
+	assert.Panics(t, func() {
+		// the 100th zero would only be padding
+		interleaved.Select(false, 100)
+	})
+	assert.Panics(t, func() {
+		interleaved.Access(100)
+	})
+	assert.Panics(t, func() {
+		interleaved.Rank(true, 101)
+	})
+}
+
+func TestInterleavedRankAtEndOfFullLine(t *testing.T) {
+	vec := bit.MakeVector(bit.InterleavedSubvectorCount * bit.SubvectorBits)
+	vec.Set(0)
+
+	interleaved := bit.NewInterleavedVector(vec)
+
+	assert.Equal(t, uint64(1), interleaved.Rank(true, vec.Bits()))
+	assert.Equal(t, vec.Bits()-1, interleaved.Rank(false, vec.Bits()))
+}
+
+func TestInterleavedTryQueries(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(bit.NewVector("0110"))
+
+	_, err := interleaved.TryAccess(4)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+	_, err = interleaved.TryRank(true, 5)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+	_, err = interleaved.TrySelect(true, 0)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+	_, err = interleaved.TrySelect(true, 3)
+	assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+
+	rank, err := interleaved.TryRank(true, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), rank)
+
+	pos, err := interleaved.TrySelect(false, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), pos)
+}
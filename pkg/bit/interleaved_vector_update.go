@@ -0,0 +1,94 @@
+package bit
+
+import "errors"
+
+// Changing bits with Set, Unset and Flip keeps track of the stale pre sums.
+//
+// A change only updates the total count and marks the pre sums of all
+// following lines as stale. Flush repairs them in one pass from the first
+// stale line and rebuilds the select samples, so a batch of changes costs a
+// single pass. Until then Rank, Select and the queries built on them panic
+// with ErrPendingChanges, their Try variants return it. Access is always
+// answered. Queries never write to the vector, so they can run concurrently
+// as long as no change runs at the same time.
+//
+// A vector from NewInterleavedVectorNoPrecompute has no valid pre sums and
+// counts as changed until Precompute. Writing to a subvector returned by
+// GetSubvector bypasses the tracking and requires a call to Precompute.
+
+// Rank or Select on a vector with changes that were not flushed
+var ErrPendingChanges = errors.New("interleaved vector has pending changes, call Flush")
+
+// Set implements Setable.
+func (i *InterleavedVector) Set(position uint64) {
+	i.write(position, true)
+}
+
+// Clear the bit at position
+func (i *InterleavedVector) Unset(position uint64) {
+	i.write(position, false)
+}
+
+// Invert the bit at position
+func (i *InterleavedVector) Flip(position uint64) {
+	i.write(position, !i.Access(position))
+}
+
+func (i *InterleavedVector) write(position uint64, b bool) {
+	i.checkWritable()
+	i.checkPosition(position)
+
+	pos, sv := i.GetSubvector(position)
+	if sv.Access(pos) == b {
+		return
+	}
+
+	if b {
+		sv.Set(pos)
+		i.ones++
+	} else {
+		sv.Unset(pos)
+		i.ones--
+	}
+
+	line := int(position / (InterleavedSubvectorCount * SubvectorBits))
+	i.dirtyFrom = min(i.dirtyFrom, line+1)
+	i.changed = true
+}
+
+func (i *InterleavedVector) checkFlushed() error {
+	// a change in the last line leaves the pre sums valid, but not the samples
+	if i.dirtyFrom < len(i.vec) || i.changed && i.sampleRate > 0 {
+		return ErrPendingChanges
+	}
+	return nil
+}
+
+// Recompute the stale pre sums and the select samples after changes, if
+// there are any
+func (i *InterleavedVector) Flush() {
+	if !i.changed && i.dirtyFrom >= len(i.vec) {
+		return
+	}
+
+	if i.dirtyFrom < len(i.vec) {
+		// the pre sum of the line before the first stale one is still valid
+		start := max(i.dirtyFrom-1, 0)
+		var sum uint64
+		if start > 0 {
+			sum = i.vec[start].PreSum
+		}
+		linePopcounts(i.vec[start:])
+
+		for j := start; j < len(i.vec); j++ {
+			ones := i.vec[j].PreSum
+			i.vec[j].PreSum = sum
+			sum += ones
+		}
+
+		i.ones = sum
+	}
+
+	// a change in the last line only changed the total, which write keeps
+	i.finishPrecompute(i.ones)
+}
@@ -0,0 +1,119 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleavedUpdatesKeepCountersConsistent(t *testing.T) {
+	vec := randomDensityVector(20_000, 300)
+	reference := bit.NewVectorFromSubvectors(append([]bit.Subvector(nil), vec.Subvectors()...), vec.Bits())
+
+	interleaved := bit.NewInterleavedVector(vec)
+	interleaved.SetSelectSampleRate(64)
+
+	naiveRank := bit.RankableBaseline{Vector: reference}
+	naiveSelect := bit.SelectableBaseline{Vector: reference}
+
+	for round := 0; round < 50; round++ {
+		// a batch of changes, then queries
+		for k := 0; k < 20; k++ {
+			pos := uint64(rand.Int63n(int64(vec.Bits())))
+
+			switch rand.Intn(3) {
+			case 0:
+				interleaved.Set(pos)
+				reference.Set(pos)
+			case 1:
+				interleaved.Unset(pos)
+				reference.Unset(pos)
+			case 2:
+				interleaved.Flip(pos)
+				if reference.Access(pos) {
+					reference.Unset(pos)
+				} else {
+					reference.Set(pos)
+				}
+			}
+			assert.Equal(t, reference.Access(pos), interleaved.Access(pos))
+		}
+
+		interleaved.Flush()
+
+		ones := reference.Ones()
+		assert.Equal(t, ones, interleaved.Rank(true, interleaved.Bits()))
+
+		for k := 0; k < 10; k++ {
+			pos := uint64(rand.Int63n(int64(vec.Bits())))
+			assert.Equal(t, naiveRank.Rank(true, pos), interleaved.Rank(true, pos))
+
+			n := uint64(rand.Int63n(int64(ones))) + 1
+			assert.Equal(t, naiveSelect.Select(true, n), interleaved.Select(true, n))
+
+			n = uint64(rand.Int63n(int64(vec.Bits()-ones))) + 1
+			assert.Equal(t, naiveSelect.Select(false, n), interleaved.Select(false, n))
+		}
+	}
+}
+
+func TestInterleavedSetOnSetBitIsNoop(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(bit.NewVector("0110"))
+
+	interleaved.Set(1)
+	interleaved.Unset(0)
+	assert.Equal(t, uint64(2), interleaved.Rank(true, 4))
+
+	interleaved.Flip(0)
+	assert.Equal(t, uint64(3), interleaved.Rank(true, 4))
+	assert.Equal(t, uint64(3), interleaved.Select(false, 1))
+}
+
+func TestInterleavedQueriesRequireFlush(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(randomDensityVector(5000, 500))
+	interleaved.Flip(10)
+
+	assert.PanicsWithValue(t, bit.ErrPendingChanges, func() { interleaved.Rank(true, 100) })
+	assert.PanicsWithValue(t, bit.ErrPendingChanges, func() { interleaved.Select(true, 1) })
+	_, err := interleaved.TryRank(true, 100)
+	assert.ErrorIs(t, err, bit.ErrPendingChanges)
+	_, err = interleaved.MarshalBinary()
+	assert.ErrorIs(t, err, bit.ErrPendingChanges)
+
+	// access does not depend on the pre sums
+	assert.NotPanics(t, func() { interleaved.Access(10) })
+
+	interleaved.Flush()
+	_, err = interleaved.TryRank(true, 100)
+	assert.NoError(t, err)
+}
+
+func TestInterleavedNoPrecomputeRequiresPrecompute(t *testing.T) {
+	vec := randomDensityVector(5000, 500)
+	interleaved := bit.NewInterleavedVectorNoPrecompute(vec)
+
+	_, err := interleaved.TryRank(true, 100)
+	assert.ErrorIs(t, err, bit.ErrPendingChanges)
+
+	interleaved.Precompute()
+	assert.Equal(t, vec.Ones(), interleaved.Rank(true, vec.Bits()))
+}
+
+func TestInterleavedLastLineChangeRebuildsSamples(t *testing.T) {
+	interleaved := bit.NewInterleavedVector(bit.NewVector("1010000000"))
+	interleaved.SetSelectSampleRate(1)
+
+	// the only line is the last one, its pre sum stays valid
+	interleaved.Set(1)
+	_, err := interleaved.TrySelect(true, 3)
+	assert.ErrorIs(t, err, bit.ErrPendingChanges)
+
+	interleaved.Flush()
+	n, err := interleaved.TrySelect(true, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), n)
+	assert.Equal(t, uint64(1), interleaved.Select(true, 2))
+	assert.Equal(t, uint64(3), interleaved.Select(false, 1))
+}
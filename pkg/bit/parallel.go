@@ -0,0 +1,55 @@
+package bit
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers used when the caller asks for 0
+func defaultWorkers(workers int) int {
+	if workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// Split [0, n) into at most workers contiguous chunks of at least minChunk
+// elements. Chunk c is [bounds[c], bounds[c+1]), there is always at least one.
+func splitChunks(n, workers, minChunk int) (bounds []int) {
+	workers = min(defaultWorkers(workers), max(n/minChunk, 1))
+	chunk := max((n+workers-1)/workers, 1)
+
+	bounds = append(bounds, 0)
+	for start := 0; start < n; start += chunk {
+		bounds = append(bounds, min(start+chunk, n))
+	}
+	if n == 0 {
+		bounds = append(bounds, 0)
+	}
+	return bounds
+}
+
+// Call f for every chunk, each in its own goroutine when there is more than one
+func runChunks(bounds []int, f func(chunk, start, end int)) {
+	if len(bounds) <= 2 {
+		f(0, bounds[0], bounds[len(bounds)-1])
+		return
+	}
+
+	var wg sync.WaitGroup
+	for c := 0; c+1 < len(bounds); c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			f(c, bounds[c], bounds[c+1])
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Split [0, n) into chunks and call f for every chunk in parallel
+func parallelChunks(n, workers, minChunk int, f func(start, end int)) {
+	runChunks(splitChunks(n, workers, minChunk), func(_, start, end int) {
+		f(start, end)
+	})
+}
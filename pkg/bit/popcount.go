@@ -0,0 +1,40 @@
+package bit
+
+import "math/bits"
+
+// Counting ones in bulk can use a vectorized implementation (AVX2 on amd64),
+// chosen at startup. A call into it only pays off for longer slices, short
+// ones like the subvectors of a single line are counted word by word.
+
+// Below this many subvectors onesCount does not use popcountBulk
+const popcountBulkMinWords = 32
+
+// Vectorized count of the ones, nil if the CPU has none
+var popcountBulk func(subvectors []Subvector) uint64
+
+// Store the ones of every line in its pre sum
+var linePopcounts = linePopcountsGo
+
+// Count the ones in a slice of subvectors
+func onesCount(subvectors []Subvector) uint64 {
+	if popcountBulk != nil && len(subvectors) >= popcountBulkMinWords {
+		return popcountBulk(subvectors)
+	}
+	return onesCountGo(subvectors)
+}
+
+func onesCountGo(subvectors []Subvector) uint64 {
+	var sum uint64 = 0
+
+	for _, v := range subvectors {
+		sum += uint64(bits.OnesCount64(uint64(v)))
+	}
+
+	return sum
+}
+
+func linePopcountsGo(lines []InterleavedVectorLine) {
+	for j := range lines {
+		lines[j].PreSum = onesCountGo(lines[j].Vec[:])
+	}
+}
@@ -0,0 +1,18 @@
+package bit
+
+// Implemented in popcount_amd64.s
+func popcountAVX2(subvectors []Subvector) uint64
+func linePopcountsAVX2(lines []InterleavedVectorLine)
+
+func init() {
+	if hasAVX2 {
+		popcountBulk = onesCountAVX2
+		linePopcounts = linePopcountsAVX2
+	}
+}
+
+// popcountAVX2 counts 4 subvectors at a time, the rest is counted here
+func onesCountAVX2(subvectors []Subvector) uint64 {
+	n := len(subvectors) &^ 3
+	return popcountAVX2(subvectors[:n]) + onesCountGo(subvectors[n:])
+}
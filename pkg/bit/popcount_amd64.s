@@ -0,0 +1,99 @@
+#include "textflag.h"
+
+// Popcount of 32 bytes at a time by looking up the popcount of every nibble
+// with VPSHUFB (Muła, Kurz and Lemire). VPSADBW sums the bytes into four
+// 64 bit counters.
+
+// popcount of the nibbles 0 to 15, twice for both lanes
+DATA popcntNibbles<>+0(SB)/8, $0x0302020102010100
+DATA popcntNibbles<>+8(SB)/8, $0x0403030203020201
+DATA popcntNibbles<>+16(SB)/8, $0x0302020102010100
+DATA popcntNibbles<>+24(SB)/8, $0x0403030203020201
+GLOBL popcntNibbles<>(SB), RODATA|NOPTR, $32
+
+DATA popcntLowNibble<>+0(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA popcntLowNibble<>+8(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA popcntLowNibble<>+16(SB)/8, $0x0f0f0f0f0f0f0f0f
+DATA popcntLowNibble<>+24(SB)/8, $0x0f0f0f0f0f0f0f0f
+GLOBL popcntLowNibble<>(SB), RODATA|NOPTR, $32
+
+// all bytes of a line but the pre sum
+DATA popcntNoPreSum<>+0(SB)/8, $0x0000000000000000
+DATA popcntNoPreSum<>+8(SB)/8, $0xffffffffffffffff
+DATA popcntNoPreSum<>+16(SB)/8, $0xffffffffffffffff
+DATA popcntNoPreSum<>+24(SB)/8, $0xffffffffffffffff
+GLOBL popcntNoPreSum<>(SB), RODATA|NOPTR, $32
+
+// Replace the bytes of reg by their popcount, clobbers tmp
+#define BYTE_POPCOUNT(reg, tmp) \
+	VPSRLW  $4, reg, tmp    \
+	VPAND   Y7, reg, reg    \
+	VPAND   Y7, tmp, tmp    \
+	VPSHUFB reg, Y6, reg    \
+	VPSHUFB tmp, Y6, tmp    \
+	VPADDB  tmp, reg, reg
+
+// Sum of the four 64 bit counters of reg into dst, clobbers X9
+#define HORIZONTAL_SUM(reg, xreg, dst) \
+	VEXTRACTI128 $1, reg, X9 \
+	VPADDQ       X9, xreg, xreg \
+	VPSHUFD      $0x4e, xreg, X9 \
+	VPADDQ       X9, xreg, xreg \
+	VMOVQ        xreg, dst
+
+// func popcountAVX2(subvectors []Subvector) uint64
+// The length has to be a multiple of 4.
+TEXT ·popcountAVX2(SB), NOSPLIT, $0-32
+	MOVQ    subvectors_base+0(FP), SI
+	MOVQ    subvectors_len+8(FP), CX
+	SHRQ    $2, CX
+	VMOVDQU popcntNibbles<>(SB), Y6
+	VMOVDQU popcntLowNibble<>(SB), Y7
+	VPXOR   Y5, Y5, Y5
+	VPXOR   Y0, Y0, Y0
+	TESTQ   CX, CX
+	JZ      sum
+
+loop:
+	VMOVDQU (SI), Y1
+	BYTE_POPCOUNT(Y1, Y2)
+	VPSADBW Y5, Y1, Y1
+	VPADDQ  Y1, Y0, Y0
+	ADDQ    $32, SI
+	DECQ    CX
+	JNZ     loop
+
+sum:
+	HORIZONTAL_SUM(Y0, X0, AX)
+	VZEROUPPER
+	MOVQ AX, ret+24(FP)
+	RET
+
+// func linePopcountsAVX2(lines []InterleavedVectorLine)
+TEXT ·linePopcountsAVX2(SB), NOSPLIT, $0-24
+	MOVQ    lines_base+0(FP), SI
+	MOVQ    lines_len+8(FP), CX
+	VMOVDQU popcntNibbles<>(SB), Y6
+	VMOVDQU popcntLowNibble<>(SB), Y7
+	VMOVDQU popcntNoPreSum<>(SB), Y8
+	VPXOR   Y5, Y5, Y5
+	TESTQ   CX, CX
+	JZ      done
+
+line:
+	VMOVDQU (SI), Y1
+	VPAND   Y8, Y1, Y1
+	VMOVDQU 32(SI), Y3
+	BYTE_POPCOUNT(Y1, Y2)
+	BYTE_POPCOUNT(Y3, Y4)
+	VPADDB  Y3, Y1, Y1
+	VPSADBW Y5, Y1, Y1
+	HORIZONTAL_SUM(Y1, X1, AX)
+	MOVQ    AX, (SI)
+	ADDQ    $64, SI
+	DECQ    CX
+	JNZ     line
+
+done:
+	VZEROUPPER
+	RET
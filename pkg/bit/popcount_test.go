@@ -0,0 +1,91 @@
+package bit_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomSubvectors(n int) []bit.Subvector {
+	subvectors := make([]bit.Subvector, n)
+	for i := range subvectors {
+		subvectors[i] = bit.Subvector(rand.Uint64())
+	}
+	return subvectors
+}
+
+func TestPopcountVariantsAgree(t *testing.T) {
+	variants := bit.PopcountVariants()
+	t.Logf("testing %d variants", len(variants))
+
+	for name, popcount := range variants {
+		t.Run(name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 3, 4, 5, 63, 64, 65, 1000, 1027} {
+				subvectors := randomSubvectors(n)
+
+				var expected uint64
+				for _, sv := range subvectors {
+					expected += uint64(sv.Ones())
+				}
+
+				assert.Equal(t, expected, popcount(subvectors), "%d subvectors", n)
+			}
+		})
+	}
+}
+
+func TestLinePopcountVariantsAgree(t *testing.T) {
+	for name, linePopcounts := range bit.LinePopcountVariants() {
+		t.Run(name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 2, 7, 100} {
+				lines := make([]bit.InterleavedVectorLine, n)
+				for j := range lines {
+					// the pre sum must not be counted
+					lines[j].PreSum = rand.Uint64()
+					copy(lines[j].Vec[:], randomSubvectors(len(lines[j].Vec)))
+				}
+
+				linePopcounts(lines)
+
+				for j, line := range lines {
+					var expected uint64
+					for _, sv := range line.Vec {
+						expected += uint64(sv.Ones())
+					}
+					assert.Equal(t, expected, line.PreSum, "line %d of %d", j, n)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPopcountVariants(b *testing.B) {
+	subvectors := randomSubvectors(1 << 14)
+
+	for name, popcount := range bit.PopcountVariants() {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(subvectors) * 8))
+			for i := 0; i < b.N; i++ {
+				popcount(subvectors)
+			}
+		})
+	}
+}
+
+func BenchmarkLinePopcountVariants(b *testing.B) {
+	lines := make([]bit.InterleavedVectorLine, 1<<12)
+	for j := range lines {
+		copy(lines[j].Vec[:], randomSubvectors(len(lines[j].Vec)))
+	}
+
+	for name, linePopcounts := range bit.LinePopcountVariants() {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(lines) * 64))
+			for i := 0; i < b.N; i++ {
+				linePopcounts(lines)
+			}
+		})
+	}
+}
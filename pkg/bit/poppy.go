@@ -0,0 +1,267 @@
+package bit
+
+import "sort"
+
+const (
+	// Bits covered by one absolute L0 count
+	poppyUpperBlockBits uint64 = 1 << 32
+	// Bits covered by one combined L1/L2 entry
+	poppyBasicBlockBits uint64 = 2048
+	// Bits covered by one L2 count
+	poppySubBlockBits uint64 = 512
+
+	poppyBasicBlockSubvectors = poppyBasicBlockBits / SubvectorBits
+	poppySubBlockSubvectors   = poppySubBlockBits / SubvectorBits
+
+	poppyL2Bits = 10
+	poppyL2Mask = 1<<poppyL2Bits - 1
+
+	// Every n'th alpha has its basic block sampled
+	poppySampleRate uint64 = 8192
+)
+
+var _ RankSelectVector = (*PoppyVector)(nil)
+
+// Rank and select in the style of cs-poppy by Zhou, Andersen and Kaminsky.
+// Three levels of counters:
+//   - L0: 64 bit absolute count for every 2^32 bits
+//   - L1: 32 bit count relative to L0 for every 2048 bit basic block
+//   - L2: 10 bit count for the first three 512 bit sub blocks of a basic block
+//
+// L1 and L2 of a basic block share one 64 bit entry, so a rank touches the
+// entry and the data only. Select uses the basic block of every 8192nd
+// one and zero as sample to narrow a binary search over the L1 entries.
+type PoppyVector struct {
+	bits []Subvector
+
+	upper []uint64
+	// L1 in the low 32 bits, followed by the three L2 counts
+	blocks []uint64
+	// basic block of every poppySampleRate'th zero (index 0) and one (index 1)
+	samples [2][]uint64
+
+	length uint64
+	ones   uint64
+}
+
+// Build the cs-poppy counters and samples for vec.
+// The subvectors are shared with vec, which must not be modified afterwards.
+func NewPoppyVector(vec Vector) *PoppyVector {
+	bits := vec.Subvectors()
+	length := vec.Bits()
+
+	blocks := (uint64(len(bits)) + poppyBasicBlockSubvectors - 1) / poppyBasicBlockSubvectors
+	upper := (length + poppyUpperBlockBits - 1) / poppyUpperBlockBits
+
+	p := &PoppyVector{
+		bits:   bits,
+		upper:  make([]uint64, upper),
+		blocks: make([]uint64, blocks),
+		length: length,
+	}
+
+	var total uint64
+	for blk := range blocks {
+		if blk*poppyBasicBlockBits%poppyUpperBlockBits == 0 {
+			p.upper[blk*poppyBasicBlockBits/poppyUpperBlockBits] = total
+		}
+
+		entry := total - p.upper[blk*poppyBasicBlockBits/poppyUpperBlockBits]
+
+		for sub := range poppyBasicBlockBits / poppySubBlockBits {
+			start := min(blk*poppyBasicBlockSubvectors+sub*poppySubBlockSubvectors, uint64(len(bits)))
+			end := min(start+poppySubBlockSubvectors, uint64(len(bits)))
+			ones := onesCount(bits[start:end])
+
+			// the count of the last sub block is implied by the next entry
+			if sub < poppyBasicBlockBits/poppySubBlockBits-1 {
+				entry |= ones << (32 + poppyL2Bits*sub)
+			}
+			total += ones
+		}
+
+		p.blocks[blk] = entry
+	}
+	p.ones = total
+
+	p.buildSamples()
+	return p
+}
+
+// Record the basic block holding the occurrences 1, rate+1, 2*rate+1, ... of both alphas
+func (p *PoppyVector) buildSamples() {
+	next := [2]uint64{1, 1}
+
+	for blk := range uint64(len(p.blocks)) {
+		bitsAfter := min((blk+1)*poppyBasicBlockBits, p.length)
+		onesAfter := p.ones
+		if blk+1 < uint64(len(p.blocks)) {
+			onesAfter = p.before(true, blk+1)
+		}
+
+		after := [2]uint64{bitsAfter - onesAfter, onesAfter}
+		for a := range p.samples {
+			for next[a] <= after[a] {
+				p.samples[a] = append(p.samples[a], blk)
+				next[a] += poppySampleRate
+			}
+		}
+	}
+}
+
+// Number of alphas in the whole vector
+func (p *PoppyVector) count(alpha bool) uint64 {
+	if alpha {
+		return p.ones
+	}
+	return p.length - p.ones
+}
+
+// Number of alphas before basic block blk
+func (p *PoppyVector) before(alpha bool, blk uint64) uint64 {
+	ones := p.upper[blk*poppyBasicBlockBits/poppyUpperBlockBits] + p.blocks[blk]&0xFFFFFFFF
+	if alpha {
+		return ones
+	}
+	return blk*poppyBasicBlockBits - ones
+}
+
+// Ones in sub block sub of basic block blk, only valid for the first three
+func (p *PoppyVector) subBlockOnes(blk, sub uint64) uint64 {
+	return (p.blocks[blk] >> (32 + poppyL2Bits*sub)) & poppyL2Mask
+}
+
+// Access implements RankSelectVector.
+func (p *PoppyVector) Access(position uint64) bool {
+	if err := checkAccess(position, p.length); err != nil {
+		panic(err)
+	}
+
+	return p.bits[position/SubvectorBits].Access(uint8(position % SubvectorBits))
+}
+
+// Rank implements RankSelectVector.
+func (p *PoppyVector) Rank(alpha bool, position uint64) uint64 {
+	if err := checkRank(position, p.length); err != nil {
+		panic(err)
+	}
+
+	if position == p.length {
+		return p.count(alpha)
+	}
+
+	blk := position / poppyBasicBlockBits
+	sub := position % poppyBasicBlockBits / poppySubBlockBits
+
+	rank := p.before(true, blk)
+	for k := range sub {
+		rank += p.subBlockOnes(blk, k)
+	}
+
+	w := position / SubvectorBits
+	rank += onesCount(p.bits[blk*poppyBasicBlockSubvectors+sub*poppySubBlockSubvectors : w])
+	rank += uint64(p.bits[w].Rank(true, uint8(position%SubvectorBits)))
+
+	if alpha {
+		return rank
+	}
+	return position - rank
+}
+
+// Select implements RankSelectVector.
+func (p *PoppyVector) Select(alpha bool, n uint64) uint64 {
+	if err := checkSelect(alpha, n, p.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	// the samples bound the basic blocks that can hold the n'th alpha
+	samples := p.samples[alphaIndex(alpha)]
+	s := (n - 1) / poppySampleRate
+	lo, hi := samples[s], uint64(len(p.blocks))
+	if s+1 < uint64(len(samples)) {
+		hi = samples[s+1] + 1
+	}
+
+	// last basic block with fewer than n alphas before it
+	blk := lo + uint64(sort.Search(int(hi-lo), func(i int) bool {
+		return p.before(alpha, lo+uint64(i)) >= n
+	})) - 1
+	n -= p.before(alpha, blk)
+
+	sub := uint64(0)
+	for ; sub < poppyBasicBlockBits/poppySubBlockBits-1; sub++ {
+		count := p.subBlockOnes(blk, sub)
+		if !alpha {
+			count = poppySubBlockBits - count
+		}
+
+		if n <= count {
+			break
+		}
+		n -= count
+	}
+
+	w := blk*poppyBasicBlockSubvectors + sub*poppySubBlockSubvectors
+	return w*SubvectorBits + selectInSubvectors(p.bits[w:], alpha, n)
+}
+
+// TryAccess implements RankSelectVector.
+func (p *PoppyVector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, p.length); err != nil {
+		return false, err
+	}
+	return p.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (p *PoppyVector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, p.length); err != nil {
+		return 0, err
+	}
+	return p.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (p *PoppyVector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := checkSelect(alpha, n, p.count(alpha)); err != nil {
+		return 0, err
+	}
+	return p.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (p *PoppyVector) RankRange(alpha bool, l, r uint64) uint64 {
+	return must(p.TryRankRange(alpha, l, r))
+}
+
+// SelectFrom implements RankSelectVector.
+func (p *PoppyVector) SelectFrom(alpha bool, position, k uint64) uint64 {
+	return must(p.TrySelectFrom(alpha, position, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (p *PoppyVector) TryRankRange(alpha bool, l, r uint64) (uint64, error) {
+	return tryRankRange(p, alpha, l, r)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (p *PoppyVector) TrySelectFrom(alpha bool, position, k uint64) (uint64, error) {
+	return trySelectFrom(p, alpha, position, k)
+}
+
+// Logical number of bits
+func (p *PoppyVector) Bits() uint64 {
+	return p.length
+}
+
+// Overhead implements RankSelectVector.
+func (p *PoppyVector) Overhead() uint64 {
+	// all counters, samples and the padding of the last subvector
+	return p.Size() - p.length
+}
+
+// Size implements RankSelectVector.
+func (p *PoppyVector) Size() uint64 {
+	words := len(p.bits) + len(p.upper) + len(p.blocks) + len(p.samples[0]) + len(p.samples[1])
+	return uint64(words) * 64
+}
@@ -0,0 +1,71 @@
+package bit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Range queries for structures that answer Rank and Select in constant or
+// logarithmic time, where two calls are as good as a dedicated query.
+
+type tryRankSelectable interface {
+	TryRankable
+	TrySelectable
+	Bits() uint64
+}
+
+// Number of alphas in [l, r) as the difference of two ranks
+func tryRankRange(v TryRankable, alpha bool, l, r uint64) (uint64, error) {
+	if l > r {
+		return 0, fmt.Errorf("%w: range [%d, %d)", ErrOutOfRange, l, r)
+	}
+
+	hi, err := v.TryRank(alpha, r)
+	if err != nil {
+		return 0, err
+	}
+	lo, err := v.TryRank(alpha, l)
+	if err != nil {
+		return 0, err
+	}
+
+	return hi - lo, nil
+}
+
+// Position of the k'th alpha at or after p by skipping the alphas before p
+func trySelectFrom(v tryRankSelectable, alpha bool, p, k uint64) (uint64, error) {
+	before, err := v.TryRank(alpha, p)
+	if err != nil {
+		return 0, err
+	}
+	if k == 0 {
+		return 0, checkSelect(alpha, k, 0)
+	}
+
+	// compared before adding, before+k could wrap around for a huge k
+	total, err := v.TryRank(alpha, v.Bits())
+	if err != nil {
+		return 0, err
+	}
+	if k > total-before {
+		return 0, notEnoughFrom(alpha, p, k)
+	}
+
+	pos, err := v.TrySelect(alpha, before+k)
+	if errors.Is(err, ErrNotEnoughOccurrences) {
+		return 0, notEnoughFrom(alpha, p, k)
+	}
+	return pos, err
+}
+
+func notEnoughFrom(alpha bool, p, k uint64) error {
+	return fmt.Errorf("%w: %d. %s at or after position %d requested", ErrNotEnoughOccurrences, k, alphaName(alpha), p)
+}
+
+// Value of a Try query, panicking on its error
+func must(value uint64, err error) uint64 {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
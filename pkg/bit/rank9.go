@@ -0,0 +1,208 @@
+package bit
+
+import "sort"
+
+// Subvectors per rank9 superblock
+const rank9BlockCount uint64 = 8
+
+// Bits of each relative count
+const rank9RelativeBits = 9
+const rank9RelativeMask = 1<<rank9RelativeBits - 1
+
+var _ RankSelectVector = (*Rank9Vector)(nil)
+
+// Rank9 by Vigna: for every 512 bit superblock one absolute count of the ones
+// before it and one word holding 7 counts of 9 bit, relative to the
+// superblock, for the subvectors 1 to 7 of the superblock.
+// Select does a binary search over the superblock counts.
+type Rank9Vector struct {
+	bits []Subvector
+
+	// two words per superblock: absolute count, packed relative counts
+	counts []uint64
+
+	length uint64
+	ones   uint64
+}
+
+// Build the rank9 counts for vec.
+// The subvectors are shared with vec, which must not be modified afterwards.
+func NewRank9Vector(vec Vector) *Rank9Vector {
+	bits := vec.Subvectors()
+	superblocks := (uint64(len(bits)) + rank9BlockCount - 1) / rank9BlockCount
+
+	r := &Rank9Vector{
+		bits:   bits,
+		counts: make([]uint64, 2*superblocks),
+		length: vec.Bits(),
+	}
+
+	var total uint64
+	for s := range superblocks {
+		r.counts[2*s] = total
+
+		var relative, packed uint64
+		for b := range rank9BlockCount {
+			if b > 0 {
+				packed |= relative << (rank9RelativeBits * (b - 1))
+			}
+
+			if w := s*rank9BlockCount + b; w < uint64(len(bits)) {
+				relative += uint64(bits[w].Ones())
+			}
+		}
+
+		r.counts[2*s+1] = packed
+		total += relative
+	}
+
+	r.ones = total
+	return r
+}
+
+// Number of alphas in the whole vector
+func (r *Rank9Vector) count(alpha bool) uint64 {
+	if alpha {
+		return r.ones
+	}
+	return r.length - r.ones
+}
+
+// Ones before subvector b of superblock s, relative to the superblock
+func (r *Rank9Vector) relative(s, b uint64) uint64 {
+	if b == 0 {
+		return 0
+	}
+	return (r.counts[2*s+1] >> (rank9RelativeBits * (b - 1))) & rank9RelativeMask
+}
+
+// Access implements RankSelectVector.
+func (r *Rank9Vector) Access(position uint64) bool {
+	if err := checkAccess(position, r.length); err != nil {
+		panic(err)
+	}
+
+	return r.bits[position/SubvectorBits].Access(uint8(position % SubvectorBits))
+}
+
+// Rank implements RankSelectVector.
+func (r *Rank9Vector) Rank(alpha bool, position uint64) uint64 {
+	if err := checkRank(position, r.length); err != nil {
+		panic(err)
+	}
+
+	if position == r.length {
+		return r.count(alpha)
+	}
+
+	w := position / SubvectorBits
+	s := w / rank9BlockCount
+
+	rank := r.counts[2*s] + r.relative(s, w%rank9BlockCount)
+	rank += uint64(r.bits[w].Rank(true, uint8(position%SubvectorBits)))
+
+	if alpha {
+		return rank
+	}
+	return position - rank
+}
+
+// Select implements RankSelectVector.
+func (r *Rank9Vector) Select(alpha bool, n uint64) uint64 {
+	if err := checkSelect(alpha, n, r.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	const superblockBits = rank9BlockCount * SubvectorBits
+
+	before := func(s uint64) uint64 {
+		if alpha {
+			return r.counts[2*s]
+		}
+		return s*superblockBits - r.counts[2*s]
+	}
+
+	// last superblock with fewer than n alphas before it
+	superblocks := len(r.counts) / 2
+	s := uint64(sort.Search(superblocks, func(s int) bool {
+		return before(uint64(s)) >= n
+	}) - 1)
+	n -= before(s)
+
+	// last subvector with fewer than n alphas before it, relative to the superblock
+	b := rank9BlockCount - 1
+	for ; b > 0; b-- {
+		relative := r.relative(s, b)
+		if !alpha {
+			relative = b*SubvectorBits - relative
+		}
+
+		if relative < n {
+			n -= relative
+			break
+		}
+	}
+
+	w := s*rank9BlockCount + b
+	return w*SubvectorBits + selectInSubvectors(r.bits[w:], alpha, n)
+}
+
+// TryAccess implements RankSelectVector.
+func (r *Rank9Vector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, r.length); err != nil {
+		return false, err
+	}
+	return r.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (r *Rank9Vector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, r.length); err != nil {
+		return 0, err
+	}
+	return r.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (r *Rank9Vector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := checkSelect(alpha, n, r.count(alpha)); err != nil {
+		return 0, err
+	}
+	return r.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (r *Rank9Vector) RankRange(alpha bool, left, right uint64) uint64 {
+	return must(r.TryRankRange(alpha, left, right))
+}
+
+// SelectFrom implements RankSelectVector.
+func (r *Rank9Vector) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(r.TrySelectFrom(alpha, p, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (r *Rank9Vector) TryRankRange(alpha bool, left, right uint64) (uint64, error) {
+	return tryRankRange(r, alpha, left, right)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (r *Rank9Vector) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	return trySelectFrom(r, alpha, p, k)
+}
+
+// Logical number of bits
+func (r *Rank9Vector) Bits() uint64 {
+	return r.length
+}
+
+// Overhead implements RankSelectVector.
+func (r *Rank9Vector) Overhead() uint64 {
+	// two words per superblock and the padding of the last subvector
+	return r.Size() - r.length
+}
+
+// Size implements RankSelectVector.
+func (r *Rank9Vector) Size() uint64 {
+	return uint64(len(r.bits)+len(r.counts)) * 64
+}
@@ -0,0 +1,194 @@
+package bit_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+type rankSelectStrategyBuilder func(bit.Vector) bit.RankSelectVector
+
+var rankSelectStrategies = map[string]rankSelectStrategyBuilder{
+	"interleaved": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewInterleavedVector(v)
+	},
+	"interleaved-compact": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewCompactInterleavedVector(v)
+	},
+	"interleaved-compact small superblocks": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewCompactInterleavedVectorWithSuperblockShift(v, 2)
+	},
+	"rank9": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewRank9Vector(v)
+	},
+	"poppy": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewPoppyVector(v)
+	},
+	"rrr": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewRRRVector(v)
+	},
+	"elias-fano": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewEliasFanoVectorFromVector(v)
+	},
+	"dynamic": func(v bit.Vector) bit.RankSelectVector {
+		return bit.NewDynamicVector(v)
+	},
+}
+
+// Vector with the given length and density of ones in per mille
+func randomDensityVector(length uint64, density int) bit.Vector {
+	vec := bit.MakeVector(length)
+	for i := range length {
+		if rand.Intn(1000) < density {
+			vec.Set(i)
+		}
+	}
+	return vec
+}
+
+func TestRankSelectVectorsVsNaive(t *testing.T) {
+	vectors := map[string]bit.Vector{
+		"empty":         bit.MakeVector(0),
+		"single one":    bit.NewVector("1"),
+		"unaligned":     randomDensityVector(1000, 500),
+		"sparse":        randomDensityVector(100_000, 5),
+		"dense":         randomDensityVector(100_000, 995),
+		"all zeros":     bit.MakeVector(4096),
+		"several lines": randomDensityVector(3*2048+17, 300),
+	}
+
+	for vecName, vec := range vectors {
+		naiveRank := bit.RankableBaseline{Vector: vec}
+		naiveSelect := bit.SelectableBaseline{Vector: vec}
+		ones := vec.Ones()
+		zeros := vec.Bits() - ones
+
+		for stratName, strat := range rankSelectStrategies {
+			t.Run(fmt.Sprintf("%s: %s", stratName, vecName), func(t *testing.T) {
+				rs := strat(vec)
+
+				assert.Equal(t, vec.Bits(), rs.Bits())
+				assert.Equal(t, ones, rs.Rank(true, vec.Bits()))
+				assert.Equal(t, zeros, rs.Rank(false, vec.Bits()))
+
+				for i := 0; i < 300 && vec.Bits() > 0; i++ {
+					pos := uint64(rand.Int63n(int64(vec.Bits())))
+					assert.Equal(t, vec.Access(pos), rs.Access(pos), pos)
+					assert.Equal(t, naiveRank.Rank(true, pos), rs.Rank(true, pos), pos)
+					assert.Equal(t, naiveRank.Rank(false, pos), rs.Rank(false, pos), pos)
+				}
+
+				for _, alpha := range []bool{true, false} {
+					count := rs.Rank(alpha, rs.Bits())
+					for i := 0; i < 300 && count > 0; i++ {
+						n := uint64(rand.Int63n(int64(count))) + 1
+						assert.Equal(t, naiveSelect.Select(alpha, n), rs.Select(alpha, n), n)
+					}
+					if count > 0 {
+						assert.Equal(t, naiveSelect.Select(alpha, count), rs.Select(alpha, count))
+					}
+
+					_, err := rs.TrySelect(alpha, count+1)
+					assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+					_, err = rs.TrySelect(alpha, 0)
+					assert.ErrorIs(t, err, bit.ErrOutOfRange)
+				}
+
+				for i := 0; i < 100; i++ {
+					l := uint64(rand.Int63n(int64(vec.Bits() + 1)))
+					r := l + uint64(rand.Int63n(int64(vec.Bits()-l+1)))
+					alpha := rand.Intn(2) == 1
+					assert.Equal(t, naiveRank.RankRange(alpha, l, r), rs.RankRange(alpha, l, r), "[%d, %d)", l, r)
+
+					// huge k must not wrap around when added to the rank of l
+					for _, k := range []uint64{uint64(rand.Intn(5)) + 1, math.MaxUint64 - uint64(rand.Intn(5))} {
+						expected, expectedErr := naiveSelect.TrySelectFrom(alpha, l, k)
+						actual, err := rs.TrySelectFrom(alpha, l, k)
+						assert.Equal(t, expected, actual, "%d. after %d", k, l)
+						assert.Equal(t, expectedErr, err, "%d. after %d", k, l)
+					}
+				}
+
+				_, err := rs.TryRankRange(true, 1, 0)
+				assert.ErrorIs(t, err, bit.ErrOutOfRange)
+				_, err = rs.TrySelectFrom(true, vec.Bits()+1, 1)
+				assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+				_, err = rs.TryAccess(vec.Bits())
+				assert.ErrorIs(t, err, bit.ErrOutOfRange)
+				_, err = rs.TryRank(true, vec.Bits()+1)
+				assert.ErrorIs(t, err, bit.ErrOutOfRange)
+			})
+		}
+	}
+}
+
+func TestRRRCompressesSkewedVectors(t *testing.T) {
+	const length = 1 << 20
+
+	testCases := []struct {
+		desc    string
+		density int
+		maxSize uint64
+	}{
+		{
+			desc:    "sparse",
+			density: 2,
+			maxSize: length / 4,
+		},
+		{
+			desc:    "dense",
+			density: 998,
+			maxSize: length / 4,
+		},
+		{
+			desc:    "uniform",
+			density: 500,
+			maxSize: length * 12 / 10,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			rrr := bit.NewRRRVector(randomDensityVector(length, tC.density))
+
+			assert.Less(t, rrr.Size(), tC.maxSize)
+			assert.Less(t, rrr.Overhead(), rrr.Size())
+
+			// at least the 6 bit class of every 63 bit block
+			assert.GreaterOrEqual(t, rrr.Overhead(), uint64((length+62)/63*6))
+		})
+	}
+}
+
+func TestCompactInterleavedOverhead(t *testing.T) {
+	vec := randomDensityVector(1_000_000, 500)
+
+	compact := bit.NewCompactInterleavedVector(vec)
+	interleaved := bit.NewInterleavedVector(vec)
+
+	// 16 of 512 bits per line, one word per 128 lines and the padding
+	assert.Less(t, float64(compact.Overhead())/float64(compact.Size()), 0.035)
+	assert.Less(t, compact.Overhead(), interleaved.Overhead()/3)
+}
+
+func TestCompactInterleavedFullSuperblocks(t *testing.T) {
+	// all ones, the relative counts reach their maximum in every superblock
+	const length = 3*128*496 + 100
+	vec := bit.MakeVector(length)
+	for i := range uint64(length) {
+		vec.Set(i)
+	}
+
+	compact := bit.NewCompactInterleavedVector(vec)
+	for pos := uint64(0); pos <= length; pos += 331 {
+		assert.Equal(t, pos, compact.Rank(true, pos))
+	}
+	for n := uint64(1); n <= length; n += 331 {
+		assert.Equal(t, n-1, compact.Select(true, n))
+	}
+	assert.Equal(t, uint64(length), compact.Rank(true, length))
+}
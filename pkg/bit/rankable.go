@@ -8,4 +8,13 @@ type RankableWithSize interface {
 type Rankable interface {
 	// Number of alphas before position
 	Rank(alpha bool, position uint64) uint64
+	// Number of alphas in [l, r)
+	RankRange(alpha bool, l, r uint64) uint64
+}
+
+type TryRankable interface {
+	// Like Rank, but returns ErrOutOfRange instead of panicking
+	TryRank(alpha bool, position uint64) (uint64, error)
+	// Like RankRange, but returns ErrOutOfRange instead of panicking
+	TryRankRange(alpha bool, l, r uint64) (uint64, error)
 }
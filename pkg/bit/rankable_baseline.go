@@ -1,6 +1,7 @@
 package bit
 
 var _ RankableWithSize = (*RankableBaseline)(nil)
+var _ TryRankable = (*RankableBaseline)(nil)
 
 type RankableBaseline struct {
 	Vector Vector
@@ -13,6 +14,20 @@ func (r *RankableBaseline) Size() uint64 {
 
 // Rank implements Rankable.
 func (r *RankableBaseline) Rank(alpha bool, position uint64) uint64 {
+	rank, err := r.TryRank(alpha, position)
+	if err != nil {
+		panic(err)
+	}
+
+	return rank
+}
+
+// TryRank implements TryRankable.
+func (r *RankableBaseline) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, r.Vector.Bits()); err != nil {
+		return 0, err
+	}
+
 	var rank uint64 = 0
 
 	for i := 0; i < int(position); i++ {
@@ -22,8 +37,30 @@ func (r *RankableBaseline) Rank(alpha bool, position uint64) uint64 {
 	}
 
 	if alpha {
-		return rank
+		return rank, nil
+	}
+
+	return position - rank, nil
+}
+
+// RankRange implements Rankable.
+func (r *RankableBaseline) RankRange(alpha bool, left, right uint64) uint64 {
+	return must(r.TryRankRange(alpha, left, right))
+}
+
+// TryRankRange implements TryRankable.
+func (r *RankableBaseline) TryRankRange(alpha bool, left, right uint64) (uint64, error) {
+	if err := checkInterval(left, right, r.Vector.Bits()); err != nil {
+		return 0, err
+	}
+
+	var rank uint64 = 0
+
+	for i := left; i < right; i++ {
+		if r.Vector.Access(i) == alpha {
+			rank++
+		}
 	}
 
-	return position - rank
+	return rank, nil
 }
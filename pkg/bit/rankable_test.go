@@ -2,7 +2,6 @@ package bit_test
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
 	"testing"
 
@@ -19,12 +18,25 @@ var rankStrategies = map[string]rankStragyBuilder{
 	"interleaved": func(v bit.Vector) bit.Rankable {
 		return bit.NewInterleavedVector(v)
 	},
+	"interleaved-compact": func(v bit.Vector) bit.Rankable {
+		return bit.NewCompactInterleavedVector(v)
+	},
+	"rank9": func(v bit.Vector) bit.Rankable {
+		return bit.NewRank9Vector(v)
+	},
+	"poppy": func(v bit.Vector) bit.Rankable {
+		return bit.NewPoppyVector(v)
+	},
+	"rrr": func(v bit.Vector) bit.Rankable {
+		return bit.NewRRRVector(v)
+	},
+	"elias-fano": func(v bit.Vector) bit.Rankable {
+		return bit.NewEliasFanoVectorFromVector(v)
+	},
 }
 
 func convert(input []byte) bit.Vector {
-	vecLen := int(math.Ceil(float64(len(input)) / float64(bit.SubvectorBits)))
-
-	vec := make(bit.Vector, vecLen)
+	vec := bit.MakeVector(uint64(len(input)))
 
 	for i := 0; i < len(input); i++ {
 		// pos := len(input) - i - 1
@@ -36,6 +48,16 @@ func convert(input []byte) bit.Vector {
 	return vec
 }
 
+// Random vector made of size subvectors
+func randomVector(size uint64) bit.Vector {
+	vector := bit.MakeVector(size * bit.SubvectorBits)
+	subvectors := vector.Subvectors()
+	for i := range subvectors {
+		subvectors[i] = bit.Subvector(rand.Uint64())
+	}
+	return vector
+}
+
 func TestRankable(t *testing.T) {
 
 	exampleVector := convert([]byte{0, 1, 1, 0, 1, 1, 0, 1, 0, 0})
@@ -99,10 +121,7 @@ func TestRankable(t *testing.T) {
 func BenchmarkRank(b *testing.B) {
 	// generate vector
 	const size = 8388608
-	vector := make(bit.Vector, size)
-	for i := 0; i < size; i++ {
-		vector[i] = bit.Subvector(rand.Uint64())
-	}
+	vector := randomVector(size)
 	b.ResetTimer()
 
 	for stratName, strat := range rankStrategies {
@@ -120,10 +139,7 @@ func BenchmarkRank(b *testing.B) {
 func TestRankVsNaive(t *testing.T) {
 	// generate vector
 	const size = 10_000
-	vector := make(bit.Vector, size)
-	for i := 0; i < size; i++ {
-		vector[i] = bit.Subvector(rand.Uint64())
-	}
+	vector := randomVector(size)
 
 	preparedVecs := make(map[string]bit.Rankable)
 	for stratName, strat := range rankStrategies {
@@ -0,0 +1,271 @@
+package bit
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	// Bits per RRR block, so that every offset fits into one word
+	rrrBlockBits uint64 = 63
+	// Bits needed for the class (number of ones) of a block
+	rrrClassBits uint8 = 6
+	// Blocks between two rank samples
+	rrrSampleBlocks uint64 = 32
+	rrrSampleBits          = rrrSampleBlocks * rrrBlockBits
+)
+
+// binomial[n][k] = n choose k for n, k <= 63
+var binomial [rrrBlockBits + 1][rrrBlockBits + 1]uint64
+
+// Bits needed for the offset of a block of class c
+var rrrOffsetBits [rrrBlockBits + 1]uint8
+
+func init() {
+	for n := range rrrBlockBits + 1 {
+		binomial[n][0] = 1
+		for k := uint64(1); k <= n; k++ {
+			binomial[n][k] = binomial[n-1][k-1] + binomial[n-1][k]
+		}
+	}
+
+	for c := range rrrBlockBits + 1 {
+		rrrOffsetBits[c] = uint8(bits.Len64(binomial[rrrBlockBits][c] - 1))
+	}
+}
+
+var _ RankSelectVector = (*RRRVector)(nil)
+
+// Compressed bit vector by Raman, Raman and Rao.
+// The vector is split into blocks of 63 bits. Each block is stored as its
+// class (number of ones, 6 bits) and its offset, the index of the block among
+// all blocks of the same class, which needs only log2(63 choose class) bits.
+// Blocks of very low or very high density therefore need almost no space.
+//
+// Every 32 blocks the number of ones before and the position in the offset
+// stream are sampled, so a rank decodes at most 32 classes and one block.
+type RRRVector struct {
+	classes bitStream
+	offsets bitStream
+
+	// ones before every sampled block and its position in the offset stream
+	sampleRanks   []uint64
+	sampleOffsets []uint64
+
+	length uint64
+	ones   uint64
+}
+
+// Encode vec into the RRR representation
+func NewRRRVector(vec Vector) *RRRVector {
+	length := vec.Bits()
+	blocks := (length + rrrBlockBits - 1) / rrrBlockBits
+	samples := (blocks + rrrSampleBlocks - 1) / rrrSampleBlocks
+
+	r := &RRRVector{
+		sampleRanks:   make([]uint64, 0, samples),
+		sampleOffsets: make([]uint64, 0, samples),
+		length:        length,
+	}
+
+	for k := range blocks {
+		if k%rrrSampleBlocks == 0 {
+			r.sampleRanks = append(r.sampleRanks, r.ones)
+			r.sampleOffsets = append(r.sampleOffsets, r.offsets.length)
+		}
+
+		start := k * rrrBlockBits
+		block := uint64(vec.Subvector(start, min(rrrBlockBits, length-start)))
+
+		class := uint8(bits.OnesCount64(block))
+		r.classes.append(uint64(class), rrrClassBits)
+		r.offsets.append(rrrEncode(block, class), rrrOffsetBits[class])
+		r.ones += uint64(class)
+	}
+
+	return r
+}
+
+// Index of the block among all blocks with the same class,
+// in the combinatorial number system
+func rrrEncode(block uint64, class uint8) uint64 {
+	var offset uint64
+	for i := uint64(1); block != 0; i++ {
+		pos := bits.TrailingZeros64(block)
+		offset += binomial[pos][i]
+		block &= block - 1
+	}
+	return offset
+}
+
+// Inverse of rrrEncode
+func rrrDecode(offset uint64, class uint8) Subvector {
+	var block uint64
+	c := uint64(class)
+
+	for pos := int(rrrBlockBits) - 1; c > 0; pos-- {
+		if binomial[pos][c] <= offset {
+			block |= 1 << pos
+			offset -= binomial[pos][c]
+			c--
+		}
+	}
+	return Subvector(block)
+}
+
+// Number of alphas in the whole vector
+func (r *RRRVector) count(alpha bool) uint64 {
+	if alpha {
+		return r.ones
+	}
+	return r.length - r.ones
+}
+
+func (r *RRRVector) class(block uint64) uint8 {
+	return uint8(r.classes.get(block*uint64(rrrClassBits), rrrClassBits))
+}
+
+// Walk from the sample to the given block, returns its decoded bits
+// and the number of ones before it
+func (r *RRRVector) block(block uint64) (Subvector, uint64) {
+	s := block / rrrSampleBlocks
+	rank := r.sampleRanks[s]
+	offsetPos := r.sampleOffsets[s]
+
+	for k := s * rrrSampleBlocks; k < block; k++ {
+		class := r.class(k)
+		rank += uint64(class)
+		offsetPos += uint64(rrrOffsetBits[class])
+	}
+
+	class := r.class(block)
+	return rrrDecode(r.offsets.get(offsetPos, rrrOffsetBits[class]), class), rank
+}
+
+// Access implements RankSelectVector.
+func (r *RRRVector) Access(position uint64) bool {
+	if err := checkAccess(position, r.length); err != nil {
+		panic(err)
+	}
+
+	block, _ := r.block(position / rrrBlockBits)
+	return block.Access(uint8(position % rrrBlockBits))
+}
+
+// Rank implements RankSelectVector.
+func (r *RRRVector) Rank(alpha bool, position uint64) uint64 {
+	if err := checkRank(position, r.length); err != nil {
+		panic(err)
+	}
+
+	if position == r.length {
+		return r.count(alpha)
+	}
+
+	block, rank := r.block(position / rrrBlockBits)
+	rank += uint64(block.Rank(true, uint8(position%rrrBlockBits)))
+
+	if alpha {
+		return rank
+	}
+	return position - rank
+}
+
+// Select implements RankSelectVector.
+func (r *RRRVector) Select(alpha bool, n uint64) uint64 {
+	if err := checkSelect(alpha, n, r.count(alpha)); err != nil {
+		panic(err)
+	}
+
+	before := func(s uint64) uint64 {
+		if alpha {
+			return r.sampleRanks[s]
+		}
+		return s*rrrSampleBits - r.sampleRanks[s]
+	}
+
+	// last sample with fewer than n alphas before it
+	s := uint64(sort.Search(len(r.sampleRanks), func(s int) bool {
+		return before(uint64(s)) >= n
+	}) - 1)
+	n -= before(s)
+
+	offsetPos := r.sampleOffsets[s]
+	for k := s * rrrSampleBlocks; ; k++ {
+		class := r.class(k)
+
+		count := uint64(class)
+		if !alpha {
+			count = rrrBlockBits - count
+		}
+
+		if n <= count {
+			block := rrrDecode(r.offsets.get(offsetPos, rrrOffsetBits[class]), class)
+			return k*rrrBlockBits + uint64(block.Select(alpha, uint8(n)))
+		}
+
+		n -= count
+		offsetPos += uint64(rrrOffsetBits[class])
+	}
+}
+
+// TryAccess implements RankSelectVector.
+func (r *RRRVector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, r.length); err != nil {
+		return false, err
+	}
+	return r.Access(position), nil
+}
+
+// TryRank implements RankSelectVector.
+func (r *RRRVector) TryRank(alpha bool, position uint64) (uint64, error) {
+	if err := checkRank(position, r.length); err != nil {
+		return 0, err
+	}
+	return r.Rank(alpha, position), nil
+}
+
+// TrySelect implements RankSelectVector.
+func (r *RRRVector) TrySelect(alpha bool, n uint64) (uint64, error) {
+	if err := checkSelect(alpha, n, r.count(alpha)); err != nil {
+		return 0, err
+	}
+	return r.Select(alpha, n), nil
+}
+
+// RankRange implements RankSelectVector.
+func (r *RRRVector) RankRange(alpha bool, left, right uint64) uint64 {
+	return must(r.TryRankRange(alpha, left, right))
+}
+
+// SelectFrom implements RankSelectVector.
+func (r *RRRVector) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(r.TrySelectFrom(alpha, p, k))
+}
+
+// TryRankRange implements RankSelectVector.
+func (r *RRRVector) TryRankRange(alpha bool, left, right uint64) (uint64, error) {
+	return tryRankRange(r, alpha, left, right)
+}
+
+// TrySelectFrom implements RankSelectVector.
+func (r *RRRVector) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	return trySelectFrom(r, alpha, p, k)
+}
+
+// Logical number of bits
+func (r *RRRVector) Bits() uint64 {
+	return r.length
+}
+
+// Overhead implements RankSelectVector.
+func (r *RRRVector) Overhead() uint64 {
+	// the classes and the rank samples, the offsets hold the compressed bits
+	return r.classes.size() + uint64(len(r.sampleRanks)+len(r.sampleOffsets))*64
+}
+
+// Size implements RankSelectVector.
+// The compressed size, which can be a lot smaller than Bits.
+func (r *RRRVector) Size() uint64 {
+	return r.offsets.size() + r.Overhead()
+}
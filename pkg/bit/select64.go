@@ -0,0 +1,86 @@
+package bit
+
+import "math/bits"
+
+// Select of the n'th one in a 64 bit word has three implementations:
+//   - selectTable walks the bytes and looks the position up in onesLookup
+//   - selectBroadword finds the byte with parallel byte counts and needs no table
+//   - selectPDEP deposits a single one at the n'th one of the word with the
+//     BMI2 instruction PDEP and counts its trailing zeros (amd64 only)
+//
+// select64 is the fastest one available on the running CPU. Only selectTable
+// notices a missing n'th one, the others return an undefined position, so
+// callers go through checkedSelect64.
+var select64 = selectBroadword
+
+const (
+	bytesOnes = 0x0101010101010101
+	bytesHigh = 0x8080808080808080
+)
+
+// Select with selectFunc, panicking with "not found" if x has no n'th one
+func checkedSelect64(selectFunc func(x uint64, n uint8) uint8, x uint64, n uint8) uint8 {
+	if n == 0 || int(n) > bits.OnesCount64(x) {
+		panic("not found")
+	}
+	return selectFunc(x, n)
+}
+
+func selectTable(x uint64, n uint8) uint8 {
+
+	const posMask = 0b0111
+	const validMask = 0b1000
+
+	n -= 1
+
+	for i := 0; i < int(SubvectorBits); i += 8 {
+		segment := uint8(x >> i)
+		ones := uint8(bits.OnesCount8(segment))
+		if ones < n+1 {
+			n -= ones
+		} else {
+
+			// 4 bytes per number
+			var lookupBegin uint32 = uint32(segment) * 4
+			bytePos := lookupBegin + (uint32(n) >> 1)
+			valueTuple := byte(onesLookup[bytePos])
+			value := valueTuple >> ((n % 2) * 4)
+
+			isValid := value&validMask == validMask
+			if isValid {
+				// add segment offset
+				return (value & posMask) + byte(i)
+			} else {
+				panic("not found")
+			}
+		}
+	}
+
+	// should not happen
+	panic("not found")
+}
+
+func selectBroadword(x uint64, n uint8) uint8 {
+	k := uint64(n - 1)
+
+	// ones per byte
+	s := x - (x>>1)&0x5555555555555555
+	s = s&0x3333333333333333 + (s>>2)&0x3333333333333333
+	s = (s + s>>4) & 0x0f0f0f0f0f0f0f0f
+
+	// byte i holds the ones in the bytes 0 to i, at most 64
+	prefix := s * bytesOnes
+
+	// the high bit of byte i is set if its prefix is at most k, no byte borrows
+	// because all prefixes are below 128. These bytes come before the n'th one.
+	byteIdx := uint64(bits.OnesCount64(((k*bytesOnes | bytesHigh) - prefix) & bytesHigh))
+	before := (prefix << 8 >> (8 * byteIdx)) & 0xff
+
+	// clear the remaining ones before it inside the byte
+	w := x >> (8 * byteIdx) & 0xff
+	for r := k - before; r > 0; r-- {
+		w &= w - 1
+	}
+
+	return uint8(8*byteIdx) + uint8(bits.TrailingZeros64(w))
+}
@@ -0,0 +1,16 @@
+package bit
+
+// Implemented in select64_amd64.s
+func selectPDEP(x uint64, n uint8) uint8
+
+// selectPDEP when the CPU supports it, nil otherwise
+var pdepSelect func(x uint64, n uint8) uint8
+
+func init() {
+	if hasBMI2 {
+		pdepSelect = selectPDEP
+	}
+	if fastPDEP {
+		select64 = selectPDEP
+	}
+}
@@ -0,0 +1,14 @@
+#include "textflag.h"
+
+// func selectPDEP(x uint64, n uint8) uint8
+// Deposits 1 << (n-1) into the ones of x, which leaves only the n'th one.
+TEXT ·selectPDEP(SB), NOSPLIT, $0-17
+	MOVQ    x+0(FP), AX
+	MOVBQZX n+8(FP), CX
+	DECQ    CX
+	MOVQ    $1, BX
+	SHLQ    CX, BX
+	PDEPQ   AX, BX, BX
+	TZCNTQ  BX, BX
+	MOVB    BX, ret+16(FP)
+	RET
@@ -0,0 +1,6 @@
+//go:build !amd64
+
+package bit
+
+// PDEP is only available on amd64
+var pdepSelect func(x uint64, n uint8) uint8
@@ -0,0 +1,80 @@
+package bit_test
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+// Position of the n'th one by clearing the ones before it
+func naiveSelect64(x uint64, n uint8) uint8 {
+	for ; n > 1; n-- {
+		x &= x - 1
+	}
+	return uint8(bits.TrailingZeros64(x))
+}
+
+func TestSelect64VariantsAgree(t *testing.T) {
+	words := []uint64{1, 1 << 63, math.MaxUint64, 0xAAAAAAAAAAAAAAAA, 0x8000000000000001, 0x00FF00FF00FF00FF}
+	for range 2000 {
+		// mix dense and sparse words
+		words = append(words, rand.Uint64(), rand.Uint64()&rand.Uint64()&rand.Uint64())
+	}
+
+	variants := bit.Select64Variants()
+	t.Logf("testing %d variants", len(variants))
+
+	for name, selectFunc := range variants {
+		t.Run(name, func(t *testing.T) {
+			for _, x := range words {
+				for n := uint8(1); n <= uint8(bits.OnesCount64(x)); n++ {
+					if !assert.Equal(t, naiveSelect64(x, n), selectFunc(x, n), "%d. one of %#x", n, x) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSelect64InvalidN(t *testing.T) {
+	invalid := []struct {
+		x uint64
+		n uint8
+	}{
+		{0, 1}, {1, 0}, {1, 2}, {math.MaxUint64, 0}, {math.MaxUint64, 65}, {0xAAAAAAAAAAAAAAAA, 33}, {0xFF, 255},
+	}
+
+	for name, selectFunc := range bit.Select64Variants() {
+		checked := bit.CheckedSelect64(selectFunc)
+		for _, c := range invalid {
+			assert.PanicsWithValue(t, "not found", func() { checked(c.x, c.n) }, "%s: %d. one of %#x", name, c.n, c.x)
+		}
+	}
+
+	for _, c := range invalid {
+		assert.PanicsWithValue(t, "not found", func() { bit.Subvector(c.x).OneSelect64(c.n) }, "%d. one of %#x", c.n, c.x)
+	}
+}
+
+func BenchmarkSelect64Variants(b *testing.B) {
+	words := make([]uint64, 1024)
+	ns := make([]uint8, len(words))
+	for i := range words {
+		words[i] = rand.Uint64() | 1
+		ns[i] = uint8(rand.Intn(bits.OnesCount64(words[i]))) + 1
+	}
+
+	for name, selectFunc := range bit.Select64Variants() {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				j := i % len(words)
+				selectFunc(words[j], ns[j])
+			}
+		})
+	}
+}
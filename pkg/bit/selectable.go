@@ -3,9 +3,18 @@ package bit
 type Selectable interface {
 	// Position of the n'th alpha
 	Select(alpha bool, n uint64) uint64
+	// Position of the k'th alpha at or after position p
+	SelectFrom(alpha bool, p, k uint64) uint64
 }
 
 type SelectableWithSize interface {
 	Selectable
 	Sizable
 }
+
+type TrySelectable interface {
+	// Like Select, but returns ErrOutOfRange or ErrNotEnoughOccurrences instead of panicking
+	TrySelect(alpha bool, n uint64) (uint64, error)
+	// Like SelectFrom, but returns ErrOutOfRange or ErrNotEnoughOccurrences instead of panicking
+	TrySelectFrom(alpha bool, p, k uint64) (uint64, error)
+}
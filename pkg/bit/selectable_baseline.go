@@ -1,6 +1,7 @@
 package bit
 
 var _ Selectable = (*SelectableBaseline)(nil)
+var _ TrySelectable = (*SelectableBaseline)(nil)
 
 type SelectableBaseline struct {
 	Vector
@@ -8,10 +9,20 @@ type SelectableBaseline struct {
 
 // Select implements Selectable.
 func (s *SelectableBaseline) Select(alpha bool, n uint64) uint64 {
+	pos, err := s.TrySelect(alpha, n)
+	if err != nil {
+		panic(err)
+	}
+
+	return pos
+}
+
+// TrySelect implements TrySelectable.
+func (s *SelectableBaseline) TrySelect(alpha bool, n uint64) (uint64, error) {
 	var count uint64 = 0
 
 	if n == 0 {
-		panic("n hast to be bigger than 0")
+		return 0, checkSelect(alpha, n, 0)
 	}
 
 	for i := uint64(0); i < s.Bits(); i++ {
@@ -20,9 +31,38 @@ func (s *SelectableBaseline) Select(alpha bool, n uint64) uint64 {
 		}
 
 		if count == n {
-			return i
+			return i, nil
+		}
+	}
+
+	return 0, checkSelect(alpha, n, count)
+}
+
+// SelectFrom implements Selectable.
+func (s *SelectableBaseline) SelectFrom(alpha bool, p, k uint64) uint64 {
+	return must(s.TrySelectFrom(alpha, p, k))
+}
+
+// TrySelectFrom implements TrySelectable.
+func (s *SelectableBaseline) TrySelectFrom(alpha bool, p, k uint64) (uint64, error) {
+	if err := checkRank(p, s.Bits()); err != nil {
+		return 0, err
+	}
+	if k == 0 {
+		return 0, checkSelect(alpha, k, 0)
+	}
+
+	var count uint64 = 0
+
+	for i := p; i < s.Bits(); i++ {
+		if s.Access(i) == alpha {
+			count++
+		}
+
+		if count == k {
+			return i, nil
 		}
 	}
 
-	panic("position not found")
+	return 0, notEnoughFrom(alpha, p, k)
 }
@@ -20,6 +20,26 @@ var selectStrategies = map[string]selectableStrategyBulder{
 	"interleaved": func(vec bit.Vector) bit.Selectable {
 		return bit.NewInterleavedVector(vec)
 	},
+	"interleaved sampled": func(vec bit.Vector) bit.Selectable {
+		interleaved := bit.NewInterleavedVector(vec)
+		interleaved.SetSelectSampleRate(bit.DefaultSelectSampleRate)
+		return interleaved
+	},
+	"interleaved-compact": func(vec bit.Vector) bit.Selectable {
+		return bit.NewCompactInterleavedVector(vec)
+	},
+	"rank9": func(vec bit.Vector) bit.Selectable {
+		return bit.NewRank9Vector(vec)
+	},
+	"poppy": func(vec bit.Vector) bit.Selectable {
+		return bit.NewPoppyVector(vec)
+	},
+	"rrr": func(vec bit.Vector) bit.Selectable {
+		return bit.NewRRRVector(vec)
+	},
+	"elias-fano": func(vec bit.Vector) bit.Selectable {
+		return bit.NewEliasFanoVectorFromVector(vec)
+	},
 }
 
 func BenchmarkSelect(b *testing.B) {
@@ -27,10 +47,7 @@ func BenchmarkSelect(b *testing.B) {
 	const operations = 100
 
 	// generate random vector
-	vector := make(bit.Vector, vecSize)
-	for i := 0; i < vecSize; i++ {
-		vector[i] = bit.Subvector(rand.Uint64())
-	}
+	vector := randomVector(vecSize)
 	ones := vector.Ones()
 
 	b.ResetTimer()
@@ -53,10 +70,7 @@ func BenchmarkSelect(b *testing.B) {
 func TestSelectVsNaive(t *testing.T) {
 	// generate vector
 	const size = 10_000
-	vector := make(bit.Vector, size)
-	for i := 0; i < size; i++ {
-		vector[i] = bit.Subvector(rand.Uint64())
-	}
+	vector := randomVector(size)
 
 	ones := vector.Ones()
 	zeros := vector.Bits() - ones
@@ -71,8 +85,8 @@ func TestSelectVsNaive(t *testing.T) {
 	}
 
 	for i := 0; i < 1000; i++ {
-		onePos := rand.Int63n(int64(ones))
-		zeroPos := rand.Int63n(int64(zeros))
+		onePos := rand.Int63n(int64(ones)) + 1
+		zeroPos := rand.Int63n(int64(zeros)) + 1
 
 		expected1 := naive.Select(true, uint64(onePos))
 		expected2 := naive.Select(false, uint64(zeroPos))
@@ -135,10 +149,10 @@ func TestSelectable(t *testing.T) {
 	})
 	//  0  1  2  3  4  5  6  7  8  9
 
-	otherBigVector := bit.Vector{
+	otherBigVector := bit.NewVectorFromSubvectors([]bit.Subvector{
 		bit.Subvector(0xFFFFFFFF_EEEEEEEE), // 8 #0
 		bit.Subvector(0xFFFFFFFE_FFFFFFFF), // 9 #0
-	}
+	}, 128)
 
 	testCases := []struct {
 		desc     string
@@ -223,3 +237,18 @@ func TestSelectable(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectableBaselineErrors(t *testing.T) {
+	s := &bit.SelectableBaseline{
+		Vector: convert([]byte{0, 1, 0, 1, 0}),
+	}
+
+	_, err := s.TrySelect(true, 0)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+	_, err = s.TrySelect(true, 3)
+	assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+
+	_, err = s.TrySelect(false, 4)
+	assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+}
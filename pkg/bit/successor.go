@@ -0,0 +1,12 @@
+package bit
+
+type Successor interface {
+	// First one at or after position, false if there is none
+	NextOne(position uint64) (uint64, bool)
+	// Last one at or before position, false if there is none
+	PrevOne(position uint64) (uint64, bool)
+	// First zero at or after position, false if there is none
+	NextZero(position uint64) (uint64, bool)
+	// Last zero at or before position, false if there is none
+	PrevZero(position uint64) (uint64, bool)
+}
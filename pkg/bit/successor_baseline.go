@@ -0,0 +1,64 @@
+package bit
+
+var _ Successor = (*RankableBaseline)(nil)
+var _ Successor = (*SelectableBaseline)(nil)
+
+// First alpha at or after position by accessing every bit
+func nextBaseline(vec Vector, alpha bool, position uint64) (uint64, bool) {
+	for i := position; i < vec.Bits(); i++ {
+		if vec.Access(i) == alpha {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Last alpha at or before position by accessing every bit
+func prevBaseline(vec Vector, alpha bool, position uint64) (uint64, bool) {
+	for i := min(position+1, vec.Bits()); i > 0; i-- {
+		if vec.Access(i-1) == alpha {
+			return i - 1, true
+		}
+	}
+	return 0, false
+}
+
+// NextOne implements Successor.
+func (r *RankableBaseline) NextOne(position uint64) (uint64, bool) {
+	return nextBaseline(r.Vector, true, position)
+}
+
+// PrevOne implements Successor.
+func (r *RankableBaseline) PrevOne(position uint64) (uint64, bool) {
+	return prevBaseline(r.Vector, true, position)
+}
+
+// NextZero implements Successor.
+func (r *RankableBaseline) NextZero(position uint64) (uint64, bool) {
+	return nextBaseline(r.Vector, false, position)
+}
+
+// PrevZero implements Successor.
+func (r *RankableBaseline) PrevZero(position uint64) (uint64, bool) {
+	return prevBaseline(r.Vector, false, position)
+}
+
+// NextOne implements Successor.
+func (s *SelectableBaseline) NextOne(position uint64) (uint64, bool) {
+	return nextBaseline(s.Vector, true, position)
+}
+
+// PrevOne implements Successor.
+func (s *SelectableBaseline) PrevOne(position uint64) (uint64, bool) {
+	return prevBaseline(s.Vector, true, position)
+}
+
+// NextZero implements Successor.
+func (s *SelectableBaseline) NextZero(position uint64) (uint64, bool) {
+	return nextBaseline(s.Vector, false, position)
+}
+
+// PrevZero implements Successor.
+func (s *SelectableBaseline) PrevZero(position uint64) (uint64, bool) {
+	return prevBaseline(s.Vector, false, position)
+}
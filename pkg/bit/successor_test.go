@@ -0,0 +1,60 @@
+package bit_test
+
+import (
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuccessorVsNaive(t *testing.T) {
+	vectors := map[string]bit.Vector{
+		"empty":     bit.MakeVector(0),
+		"single":    bit.NewVector("0"),
+		"unaligned": randomDensityVector(1000, 500),
+		"sparse":    randomDensityVector(20_000, 2),
+		"dense":     randomDensityVector(20_000, 998),
+	}
+
+	for name, vec := range vectors {
+		t.Run(name, func(t *testing.T) {
+			naive := &bit.RankableBaseline{Vector: vec}
+			interleaved := bit.NewInterleavedVector(vec)
+
+			for position := uint64(0); position <= vec.Bits()+1; position++ {
+				queries := map[string][2]func(uint64) (uint64, bool){
+					"next one":  {naive.NextOne, interleaved.NextOne},
+					"prev one":  {naive.PrevOne, interleaved.PrevOne},
+					"next zero": {naive.NextZero, interleaved.NextZero},
+					"prev zero": {naive.PrevZero, interleaved.PrevZero},
+				}
+
+				for query, f := range queries {
+					expected, expectedFound := f[0](position)
+					actual, found := f[1](position)
+
+					assert.Equal(t, expectedFound, found, "%s %d", query, position)
+					assert.Equal(t, expected, actual, "%s %d", query, position)
+				}
+			}
+		})
+	}
+}
+
+func TestSuccessorFlags(t *testing.T) {
+	vec := &bit.SelectableBaseline{Vector: bit.NewVector("0010")}
+
+	pos, ok := vec.NextOne(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), pos)
+
+	_, ok = vec.NextOne(3)
+	assert.False(t, ok)
+
+	_, ok = vec.PrevOne(1)
+	assert.False(t, ok)
+
+	pos, ok = vec.PrevZero(100)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), pos)
+}
@@ -15,6 +15,10 @@ func (s *Subvector) Set(pos uint8) {
 	*s |= 1 << pos
 }
 
+func (s *Subvector) Unset(pos uint8) {
+	*s &= ^(1 << pos)
+}
+
 func (s Subvector) Ones() uint8 {
 	return uint8(bits.OnesCount64(uint64(s)))
 }
@@ -42,54 +46,52 @@ func (s Subvector) Select(alpha bool, n uint8) uint8 {
 	}
 }
 
-func (s Subvector) OneSelect64(n uint8) uint8 {
+// Like Select, but returns an error instead of panicking
+func (s Subvector) TrySelect(alpha bool, n uint8) (uint8, error) {
+	count := s.Ones()
+	if !alpha {
+		count = uint8(SubvectorBits) - count
+	}
 
-	const posMask = 0b0111
-	const validMask = 0b1000
-
-	n -= 1
-
-	for i := 0; i < int(SubvectorBits); i += 8 {
-		segment := uint8(s >> i)
-		ones := uint8(bits.OnesCount8(segment))
-		if ones < n+1 {
-			n -= ones
-		} else {
-
-			// 4 bytes per number
-			var lookupBegin uint32 = uint32(segment) * 4
-			bytePos := lookupBegin + (uint32(n) >> 1)
-			valueTuple := byte(onesLookup[bytePos])
-			value := valueTuple >> ((n % 2) * 4)
-
-			isValid := value&validMask == validMask
-			if isValid {
-				// add segment offset
-				return (value & posMask) + byte(i)
-			} else {
-				panic("not found")
-			}
-		}
+	if err := checkSelect(alpha, uint64(n), uint64(count)); err != nil {
+		return 0, err
 	}
 
-	// should not happen
-	panic("not found")
+	return s.Select(alpha, n), nil
+}
+
+// Position of the n'th one, n starts at 1 and must not exceed the ones,
+// otherwise it panics with "not found".
+// The implementation is chosen for the CPU at startup, see select64.go.
+func (s Subvector) OneSelect64(n uint8) uint8 {
+	return checkedSelect64(select64, uint64(s), n)
 }
 
 var _ Accessible = (*Vector)(nil)
 var _ AccessibleWithSize = (*Vector)(nil)
 
-type Vector []Subvector
+// Vector is a plain bit vector backed by 64 bit subvectors.
+// Bits past the logical length are padding and always zero.
+type Vector struct {
+	subvectors []Subvector
+	length     uint64
+}
 
 // Size implements AccessibleWithSize.
+// This is the storage used by the subvectors, including padding.
 func (b *Vector) Size() uint64 {
-	return b.Bits()
+	return uint64(len(b.subvectors)) * SubvectorBits
 }
 
 type Accessible interface {
 	Access(position uint64) bool
 }
 
+type TryAccessible interface {
+	// Like Access, but returns ErrOutOfRange instead of panicking
+	TryAccess(position uint64) (bool, error)
+}
+
 type Setable interface {
 	Set(position uint64)
 }
@@ -104,13 +106,55 @@ type RankSelectVector interface {
 	RankableWithSize
 	SelectableWithSize
 
+	TryAccessible
+	TryRankable
+	TrySelectable
+
+	// Logical number of bits
+	Bits() uint64
+
 	// Additional bits
 	Overhead() uint64
 }
 
+// Number of subvectors needed to store length bits
+func subvectorCount(length uint64) uint64 {
+	return (length + SubvectorBits - 1) / SubvectorBits
+}
+
+// Create a zeroed vector with the given logical length
+func MakeVector(length uint64) Vector {
+	return Vector{
+		subvectors: make([]Subvector, subvectorCount(length)),
+		length:     length,
+	}
+}
+
+// Wrap existing subvectors into a vector with the given logical length.
+// The slice is not copied, padding bits in the last subvector are cleared.
+func NewVectorFromSubvectors(subvectors []Subvector, length uint64) Vector {
+	if length > uint64(len(subvectors))*SubvectorBits {
+		panic(fmt.Sprintf("length %d does not fit into %d subvectors", length, len(subvectors)))
+	}
+
+	vec := Vector{
+		subvectors: subvectors[:subvectorCount(length)],
+		length:     length,
+	}
+
+	vec.clearPadding()
+	return vec
+}
+
+// Restore the invariant that the bits past the logical length are zero
+func (b Vector) clearPadding() {
+	if rest := b.length % SubvectorBits; rest != 0 {
+		b.subvectors[len(b.subvectors)-1] &= ^(SubvectorMax << rest)
+	}
+}
+
 func NewVector(input string) Vector {
-	var noSubvectors = uint64(math.Ceil(float64(len(input)) / float64(SubvectorBits)))
-	var vec Vector = make([]Subvector, noSubvectors)
+	vec := MakeVector(uint64(len(input)))
 
 	for i := 0; i < len(input); i++ {
 		// pos := len(input) - i - 1
@@ -122,35 +166,73 @@ func NewVector(input string) Vector {
 	return vec
 }
 
+func (b Vector) checkPosition(position uint64) {
+	if err := checkAccess(position, b.length); err != nil {
+		panic(err)
+	}
+}
+
+// The underlying subvectors, changes are visible in the vector
+func (b Vector) Subvectors() []Subvector {
+	return b.subvectors
+}
+
 func (b Vector) Set(position uint64) {
+	b.checkPosition(position)
+
 	subvectorPos := position / SubvectorBits
 	bitPos := position % SubvectorBits
 
-	b[subvectorPos] |= 1 << bitPos
+	b.subvectors[subvectorPos] |= 1 << bitPos
 }
 
 func (b Vector) Unset(position uint64) {
+	b.checkPosition(position)
+
 	subvectorPos := position / SubvectorBits
 	bitPos := position % SubvectorBits
 
-	b[subvectorPos] &= ^(1 << bitPos)
+	b.subvectors[subvectorPos] &= ^(1 << bitPos)
 }
 
 func (b Vector) Access(position uint64) bool {
+	b.checkPosition(position)
+
 	subvectorPos := position / SubvectorBits
 	bitPos := position % SubvectorBits
 
-	return b[subvectorPos].Access(uint8(bitPos))
+	return b.subvectors[subvectorPos].Access(uint8(bitPos))
+}
+
+// TryAccess implements TryAccessible.
+func (b Vector) TryAccess(position uint64) (bool, error) {
+	if err := checkAccess(position, b.length); err != nil {
+		return false, err
+	}
+
+	return b.Access(position), nil
 }
 
 func (b Vector) Ones() uint64 {
-	var sum uint64 = 0
+	return onesCount(b.subvectors)
+}
+
+// Position of the n'th alpha in a slice of subvectors.
+// The caller has to make sure that enough alphas exist.
+func selectInSubvectors(subvectors []Subvector, alpha bool, n uint64) uint64 {
+	for i, sv := range subvectors {
+		count := uint64(sv.Ones())
+		if !alpha {
+			count = SubvectorBits - count
+		}
 
-	for _, v := range b {
-		sum += uint64(bits.OnesCount64(uint64(v)))
+		if n <= count {
+			return uint64(i)*SubvectorBits + uint64(sv.Select(alpha, uint8(n)))
+		}
+		n -= count
 	}
 
-	return uint64(sum)
+	panic("not found")
 }
 
 func (b Vector) Subvector(position, length uint64) Subvector {
@@ -163,16 +245,17 @@ func (b Vector) Subvector(position, length uint64) Subvector {
 
 	var sub Subvector
 
-	sub = (b[subvectorPos] >> bitPos) & ^(SubvectorMax << length)
+	sub = (b.subvectors[subvectorPos] >> bitPos) & ^(SubvectorMax << length)
 
 	// add overlap
 	if bitPos+length > SubvectorBits {
-		sub |= b[subvectorPos+1] & ^(SubvectorMax << ((bitPos + length) % SubvectorBits))
+		sub |= (b.subvectors[subvectorPos+1] & ^(SubvectorMax << ((bitPos + length) % SubvectorBits))) << (SubvectorBits - bitPos)
 	}
 
 	return sub
 }
 
+// Logical number of bits
 func (b Vector) Bits() uint64 {
-	return uint64(len(b) * int(SubvectorBits))
+	return b.length
 }
@@ -0,0 +1,136 @@
+package bit
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// Bitwise operations on vectors of the same logical length.
+// The allocating variants return a new vector, the InPlace variants overwrite
+// the receiver and return it. All results keep the padding zero, so they can
+// be passed to NewInterleavedVector directly.
+
+func (b Vector) checkSameLength(other Vector) {
+	if b.length != other.length {
+		panic(fmt.Errorf("%w: %d and %d bits", ErrLengthMismatch, b.length, other.length))
+	}
+}
+
+// Copy of the vector that does not share its subvectors
+func (b Vector) Clone() Vector {
+	return Vector{
+		subvectors: slices.Clone(b.subvectors),
+		length:     b.length,
+	}
+}
+
+// Bits set in both vectors
+func (b Vector) And(other Vector) Vector {
+	return b.Clone().AndInPlace(other)
+}
+
+// Bits set in any of the vectors
+func (b Vector) Or(other Vector) Vector {
+	return b.Clone().OrInPlace(other)
+}
+
+// Bits set in exactly one of the vectors
+func (b Vector) Xor(other Vector) Vector {
+	return b.Clone().XorInPlace(other)
+}
+
+// Bits set in b but not in other
+func (b Vector) AndNot(other Vector) Vector {
+	return b.Clone().AndNotInPlace(other)
+}
+
+// Every bit flipped
+func (b Vector) Not() Vector {
+	return b.Clone().NotInPlace()
+}
+
+// Like And, but overwrites b
+func (b Vector) AndInPlace(other Vector) Vector {
+	b.checkSameLength(other)
+	for i, sv := range other.subvectors {
+		b.subvectors[i] &= sv
+	}
+	return b
+}
+
+// Like Or, but overwrites b
+func (b Vector) OrInPlace(other Vector) Vector {
+	b.checkSameLength(other)
+	for i, sv := range other.subvectors {
+		b.subvectors[i] |= sv
+	}
+	return b
+}
+
+// Like Xor, but overwrites b
+func (b Vector) XorInPlace(other Vector) Vector {
+	b.checkSameLength(other)
+	for i, sv := range other.subvectors {
+		b.subvectors[i] ^= sv
+	}
+	return b
+}
+
+// Like AndNot, but overwrites b
+func (b Vector) AndNotInPlace(other Vector) Vector {
+	b.checkSameLength(other)
+	for i, sv := range other.subvectors {
+		b.subvectors[i] &^= sv
+	}
+	return b
+}
+
+// Like Not, but overwrites b
+func (b Vector) NotInPlace() Vector {
+	for i := range b.subvectors {
+		b.subvectors[i] = ^b.subvectors[i]
+	}
+	b.clearPadding()
+	return b
+}
+
+// Number of ones of And, without allocating it
+func (b Vector) PopcountAnd(other Vector) uint64 {
+	b.checkSameLength(other)
+	var sum uint64
+	for i, sv := range other.subvectors {
+		sum += uint64(bits.OnesCount64(uint64(b.subvectors[i] & sv)))
+	}
+	return sum
+}
+
+// Number of ones of Or, without allocating it
+func (b Vector) PopcountOr(other Vector) uint64 {
+	b.checkSameLength(other)
+	var sum uint64
+	for i, sv := range other.subvectors {
+		sum += uint64(bits.OnesCount64(uint64(b.subvectors[i] | sv)))
+	}
+	return sum
+}
+
+// Number of ones of Xor, without allocating it
+func (b Vector) PopcountXor(other Vector) uint64 {
+	b.checkSameLength(other)
+	var sum uint64
+	for i, sv := range other.subvectors {
+		sum += uint64(bits.OnesCount64(uint64(b.subvectors[i] ^ sv)))
+	}
+	return sum
+}
+
+// Number of ones of AndNot, without allocating it
+func (b Vector) PopcountAndNot(other Vector) uint64 {
+	b.checkSameLength(other)
+	var sum uint64
+	for i, sv := range other.subvectors {
+		sum += uint64(bits.OnesCount64(uint64(b.subvectors[i] &^ sv)))
+	}
+	return sum
+}
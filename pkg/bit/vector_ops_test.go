@@ -0,0 +1,65 @@
+package bit_test
+
+import (
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorOpsVsNaive(t *testing.T) {
+	const length = 1000
+	a := randomDensityVector(length, 500)
+	b := randomDensityVector(length, 300)
+
+	ops := map[string]struct {
+		result   func() bit.Vector
+		inPlace  func() bit.Vector
+		popcount func() uint64
+		expected func(x, y bool) bool
+	}{
+		"and":     {func() bit.Vector { return a.And(b) }, func() bit.Vector { return a.Clone().AndInPlace(b) }, func() uint64 { return a.PopcountAnd(b) }, func(x, y bool) bool { return x && y }},
+		"or":      {func() bit.Vector { return a.Or(b) }, func() bit.Vector { return a.Clone().OrInPlace(b) }, func() uint64 { return a.PopcountOr(b) }, func(x, y bool) bool { return x || y }},
+		"xor":     {func() bit.Vector { return a.Xor(b) }, func() bit.Vector { return a.Clone().XorInPlace(b) }, func() uint64 { return a.PopcountXor(b) }, func(x, y bool) bool { return x != y }},
+		"and not": {func() bit.Vector { return a.AndNot(b) }, func() bit.Vector { return a.Clone().AndNotInPlace(b) }, func() uint64 { return a.PopcountAndNot(b) }, func(x, y bool) bool { return x && !y }},
+		"not":     {func() bit.Vector { return a.Not() }, func() bit.Vector { return a.Clone().NotInPlace() }, nil, func(x, _ bool) bool { return !x }},
+	}
+
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			before := a.Clone()
+
+			for _, result := range []bit.Vector{op.result(), op.inPlace()} {
+				assert.Equal(t, uint64(length), result.Bits())
+
+				var ones uint64
+				for i := range uint64(length) {
+					expected := op.expected(a.Access(i), b.Access(i))
+					assert.Equal(t, expected, result.Access(i), "position %d", i)
+					if expected {
+						ones++
+					}
+				}
+
+				// the padding stays zero
+				assert.Equal(t, ones, result.Ones())
+				assert.Equal(t, ones, bit.NewInterleavedVector(result).Rank(true, length))
+				if op.popcount != nil {
+					assert.Equal(t, ones, op.popcount())
+				}
+			}
+
+			// the allocating variants leave the operands alone
+			assert.Equal(t, before.Subvectors(), a.Subvectors())
+		})
+	}
+}
+
+func TestVectorOpsLengthMismatch(t *testing.T) {
+	a := bit.MakeVector(10)
+	b := bit.MakeVector(11)
+
+	assert.PanicsWithError(t, "length mismatch: 10 and 11 bits", func() { a.And(b) })
+	assert.Panics(t, func() { a.XorInPlace(b) })
+	assert.Panics(t, func() { a.PopcountAnd(b) })
+}
@@ -39,7 +39,7 @@ func TestBitVectorSetAndGet(t *testing.T) {
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
 
-			bv := make(bit.Vector, tC.size)
+			bv := bit.MakeVector(tC.size * bit.SubvectorBits)
 
 			bv.Set(tC.position)
 			t.Logf("%b", bv)
@@ -52,7 +52,7 @@ func TestBitVectorSetAndGet(t *testing.T) {
 }
 
 func TestSubvector(t *testing.T) {
-	vect := bit.Vector{0xFF00FF00FF00FF00, 0xFF00FF00FF00FF00}
+	vect := bit.NewVectorFromSubvectors([]bit.Subvector{0xFF00FF00FF00FF00, 0xFF00FF00FF00FF00}, 128)
 
 	testCases := []struct {
 		desc             string
@@ -81,6 +81,20 @@ func TestSubvector(t *testing.T) {
 			expected: 0x0F,
 			vec:      vect,
 		},
+		{
+			desc:     "overlap with ones in the next subvector",
+			position: 56,
+			length:   16,
+			expected: 0x00FF,
+			vec:      vect,
+		},
+		{
+			desc:     "overlap into the next subvector",
+			position: 40,
+			length:   40,
+			expected: 0xFF00FF_00FF,
+			vec:      vect,
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -209,7 +223,7 @@ func TestOneSelect64_WithOnlyOnes(t *testing.T) {
 
 func BenchmarkSubvectorSelect(b *testing.B) {
 
-	vec := make(bit.Vector, b.N)
+	vec := make([]bit.Subvector, b.N)
 	for i := range len(vec) {
 		vec[i] = bit.SubvectorMax
 	}
@@ -298,3 +312,37 @@ func TestSubvectorAccess(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorLogicalLength(t *testing.T) {
+	vec := bit.NewVector("0110")
+
+	assert.Equal(t, uint64(4), vec.Bits())
+	assert.Equal(t, uint64(64), vec.Size())
+	assert.Equal(t, uint64(2), vec.Ones())
+
+	assert.Panics(t, func() {
+		vec.Access(4)
+	})
+	assert.Panics(t, func() {
+		vec.Set(4)
+	})
+}
+
+func TestNewVectorFromSubvectorsClearsPadding(t *testing.T) {
+	vec := bit.NewVectorFromSubvectors([]bit.Subvector{bit.SubvectorMax, bit.SubvectorMax}, 70)
+
+	assert.Equal(t, uint64(70), vec.Bits())
+	assert.Equal(t, uint64(70), vec.Ones())
+}
+
+func TestSubvectorTrySelect(t *testing.T) {
+	_, err := bit.Subvector(0b101).TrySelect(true, 3)
+	assert.ErrorIs(t, err, bit.ErrNotEnoughOccurrences)
+
+	_, err = bit.Subvector(0b101).TrySelect(false, 0)
+	assert.ErrorIs(t, err, bit.ErrOutOfRange)
+
+	pos, err := bit.Subvector(0b101).TrySelect(true, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), pos)
+}
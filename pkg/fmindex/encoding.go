@@ -0,0 +1,204 @@
+package fmindex
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// On-disk format of an Index, all integers are little-endian.
+//
+//	offset  size       field
+//	0       4          magic "FMIX"
+//	4       2          format version
+//	6       2          reserved, zero
+//	8       8          text length n
+//	16      8          sample rate
+//	24      8          number of samples m
+//	32      n+1        BWT, the sentinel is stored as 0
+//	...     8          row of the sentinel
+//	...     16*m       samples, each the row followed by its suffix start
+//	...     4          CRC-32 (Castagnoli) of everything before
+//
+// The wavelet tree, the counts and the inverse samples are rebuilt on load.
+const (
+	EncodingMagic   = "FMIX"
+	EncodingVersion = 1
+
+	encodingHeaderSize = 32
+
+	// bytes read at once, the header is not trusted to size the buffers
+	encodingChunkSize = 64 * 1024
+)
+
+var (
+	ErrInvalidFormat      = errors.New("invalid fm-index format")
+	ErrUnsupportedVersion = errors.New("unsupported fm-index format version")
+	ErrChecksumMismatch   = errors.New("fm-index checksum mismatch")
+)
+
+var _ io.WriterTo = (*Index)(nil)
+var _ io.ReaderFrom = (*Index)(nil)
+
+var encodingTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WriteTo implements io.WriterTo.
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	crc := crc32.New(encodingTable)
+	out := bufio.NewWriter(io.MultiWriter(counter, crc))
+
+	header := make([]byte, encodingHeaderSize)
+	copy(header[0:4], EncodingMagic)
+	binary.LittleEndian.PutUint16(header[4:6], EncodingVersion)
+	binary.LittleEndian.PutUint64(header[8:16], idx.length)
+	binary.LittleEndian.PutUint64(header[16:24], idx.sampleRate)
+	binary.LittleEndian.PutUint64(header[24:32], uint64(len(idx.saSamples)))
+	out.Write(header)
+
+	var sentinel uint64
+	for row := range idx.length + 1 {
+		symbol := idx.bwt.Access(row)
+		if symbol == 0 {
+			sentinel = row
+			out.WriteByte(0)
+		} else {
+			out.WriteByte(byte(symbol - 1))
+		}
+	}
+
+	buf := binary.LittleEndian.AppendUint64(nil, sentinel)
+	for i, p := range idx.saSamples {
+		buf = binary.LittleEndian.AppendUint64(buf, idx.sampled.Select(true, uint64(i)+1))
+		buf = binary.LittleEndian.AppendUint64(buf, p)
+	}
+	out.Write(buf)
+
+	if err := out.Flush(); err != nil {
+		return counter.n, err
+	}
+
+	_, err := counter.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32()))
+	return counter.n, err
+}
+
+// ReadFrom implements io.ReaderFrom.
+// The index is replaced by the decoded one.
+func (idx *Index) ReadFrom(r io.Reader) (int64, error) {
+	counter := &countingReader{r: r}
+	crc := crc32.New(encodingTable)
+	in := io.TeeReader(counter, crc)
+
+	header := make([]byte, encodingHeaderSize)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return counter.n, truncated(err)
+	}
+
+	if string(header[0:4]) != EncodingMagic {
+		return counter.n, fmt.Errorf("%w: bad magic", ErrInvalidFormat)
+	}
+	if version := binary.LittleEndian.Uint16(header[4:6]); version != EncodingVersion {
+		return counter.n, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	length := binary.LittleEndian.Uint64(header[8:16])
+	rate := binary.LittleEndian.Uint64(header[16:24])
+	samples := binary.LittleEndian.Uint64(header[24:32])
+	// New rejects such texts, this also keeps length+1 and 16*samples from overflowing
+	if length >= math.MaxInt32 {
+		return counter.n, fmt.Errorf("%w: length %d", ErrInvalidFormat, length)
+	}
+	if rate == 0 || samples != length/rate+1 {
+		return counter.n, fmt.Errorf("%w: %d samples at rate %d for length %d", ErrInvalidFormat, samples, rate, length)
+	}
+
+	bwtBytes, err := readChunked(in, length+1)
+	if err != nil {
+		return counter.n, err
+	}
+
+	buf, err := readChunked(in, 8+16*samples)
+	if err != nil {
+		return counter.n, err
+	}
+
+	// the checksum covers everything before the trailer
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(counter, trailer); err != nil {
+		return counter.n, truncated(err)
+	}
+	if binary.LittleEndian.Uint32(trailer) != crc.Sum32() {
+		return counter.n, ErrChecksumMismatch
+	}
+
+	sentinel := binary.LittleEndian.Uint64(buf)
+	if sentinel > length {
+		return counter.n, fmt.Errorf("%w: sentinel in row %d", ErrInvalidFormat, sentinel)
+	}
+
+	bwt := make([]uint32, length+1)
+	for row, b := range bwtBytes {
+		bwt[row] = uint32(b) + 1
+	}
+	bwt[sentinel] = 0
+
+	rows := make([]uint64, samples)
+	saSamples := make([]uint64, samples)
+	for i := range samples {
+		rows[i] = binary.LittleEndian.Uint64(buf[8+16*i:])
+		saSamples[i] = binary.LittleEndian.Uint64(buf[16+16*i:])
+
+		if rows[i] > length || saSamples[i] > length || saSamples[i]%rate != 0 || (i > 0 && rows[i] <= rows[i-1]) {
+			return counter.n, fmt.Errorf("%w: sample %d", ErrInvalidFormat, i)
+		}
+	}
+
+	*idx = *newIndex(bwt, rate, rows, saSamples)
+	return counter.n, nil
+}
+
+// Read n bytes, growing the buffer only as the data arrives
+func readChunked(r io.Reader, n uint64) ([]byte, error) {
+	buf := make([]byte, 0, min(n, encodingChunkSize))
+	for uint64(len(buf)) < n {
+		chunk := min(n-uint64(len(buf)), encodingChunkSize)
+		buf = append(buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(r, buf[uint64(len(buf))-chunk:]); err != nil {
+			return nil, truncated(err)
+		}
+	}
+	return buf, nil
+}
+
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated", ErrInvalidFormat)
+	}
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,197 @@
+package fmindex
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/paulheg/kit_advanced_data_structures/pkg/wavelet"
+)
+
+// FM-index of Ferragina and Manzini over a byte string.
+// The Burrows-Wheeler transform (BWT) is the column of characters preceding
+// the sorted suffixes of the text followed by a sentinel. A pattern is
+// searched backwards: with C[c] the number of characters smaller than c and
+// Rank on the BWT, the rows of all suffixes starting with cP follow from the
+// rows starting with P. The BWT is stored in a wavelet tree, so every step
+// costs one Rank per level.
+//
+// Characters are stored as byte+1 to keep 0 free for the sentinel.
+
+// Every DefaultSampleRate'th text position has its suffix array entry stored
+const DefaultSampleRate = 32
+
+const alphabetSize = 257
+
+type Index struct {
+	bwt *wavelet.Tree
+	// number of characters smaller than c, index alphabetSize is the total
+	c [alphabetSize + 1]uint64
+	// text length without the sentinel
+	length uint64
+
+	sampleRate uint64
+	// rows whose suffix starts at a multiple of sampleRate
+	sampled *bit.InterleavedVector
+	// suffix start of every sampled row, in row order
+	saSamples []uint64
+	// row of the suffix starting at j*sampleRate
+	isaSamples []uint64
+}
+
+// Index text with DefaultSampleRate
+func New(text []byte) *Index {
+	return NewWithSampleRate(text, DefaultSampleRate)
+}
+
+// Index text, storing the suffix array entry of every rate'th position.
+// Larger rates make the index smaller and Locate and Extract slower.
+func NewWithSampleRate(text []byte, rate uint64) *Index {
+	if rate == 0 {
+		panic(fmt.Errorf("%w: sample rate 0", bit.ErrOutOfRange))
+	}
+	if uint64(len(text)) >= math.MaxInt32 {
+		panic(fmt.Errorf("%w: text of %d bytes", bit.ErrOutOfRange, len(text)))
+	}
+
+	s := make([]int32, len(text)+1)
+	for i, b := range text {
+		s[i] = int32(b) + 1
+	}
+	sa := sais(s, alphabetSize)
+
+	bwt := make([]uint32, len(sa))
+	for row, p := range sa {
+		if p > 0 {
+			bwt[row] = uint32(s[p-1])
+		}
+	}
+
+	var samples []uint64
+	var rows []uint64
+	for row, p := range sa {
+		if uint64(p)%rate == 0 {
+			rows = append(rows, uint64(row))
+			samples = append(samples, uint64(p))
+		}
+	}
+
+	return newIndex(bwt, rate, rows, samples)
+}
+
+// Build the index from the BWT and the sampled suffix array entries
+func newIndex(bwt []uint32, rate uint64, rows, samples []uint64) *Index {
+	idx := &Index{
+		bwt:        wavelet.NewTree(bwt),
+		length:     uint64(len(bwt)) - 1,
+		sampleRate: rate,
+		saSamples:  samples,
+	}
+
+	for _, symbol := range bwt {
+		idx.c[symbol+1]++
+	}
+	for symbol := 1; symbol <= alphabetSize; symbol++ {
+		idx.c[symbol] += idx.c[symbol-1]
+	}
+
+	marks := bit.MakeVector(uint64(len(bwt)))
+	for _, row := range rows {
+		marks.Set(row)
+	}
+	idx.sampled = bit.NewInterleavedVector(marks)
+
+	// every multiple of the rate is sampled, so the inverse follows from the samples
+	idx.isaSamples = make([]uint64, idx.length/rate+1)
+	for i, p := range samples {
+		idx.isaSamples[p/rate] = rows[i]
+	}
+
+	return idx
+}
+
+// Length of the indexed text
+func (idx *Index) Len() uint64 {
+	return idx.length
+}
+
+// Row of the suffix one position before the suffix of row
+func (idx *Index) lf(row uint64) uint64 {
+	symbol := idx.bwt.Access(row)
+	return idx.c[symbol] + idx.bwt.Rank(symbol, row)
+}
+
+// Rows [start, end) of the suffixes starting with pattern
+func (idx *Index) search(pattern []byte) (start, end uint64) {
+	start, end = 0, idx.length+1
+
+	for i := len(pattern) - 1; i >= 0 && start < end; i-- {
+		symbol := uint32(pattern[i]) + 1
+		start = idx.c[symbol] + idx.bwt.Rank(symbol, start)
+		end = idx.c[symbol] + idx.bwt.Rank(symbol, end)
+	}
+
+	return start, max(start, end)
+}
+
+// Number of occurrences of pattern in the text
+func (idx *Index) Count(pattern []byte) uint64 {
+	start, end := idx.search(pattern)
+	return end - start
+}
+
+// Start positions of all occurrences of pattern, in increasing order
+func (idx *Index) Locate(pattern []byte) []uint64 {
+	start, end := idx.search(pattern)
+
+	positions := make([]uint64, 0, end-start)
+	for row := start; row < end; row++ {
+		positions = append(positions, idx.suffix(row))
+	}
+
+	slices.Sort(positions)
+	return positions
+}
+
+// Start of the suffix of row, by walking back to the next sampled row
+func (idx *Index) suffix(row uint64) uint64 {
+	var steps uint64
+	for !idx.sampled.Access(row) {
+		row = idx.lf(row)
+		steps++
+	}
+	return idx.saSamples[idx.sampled.Rank(true, row)] + steps
+}
+
+// The text in [from, to)
+func (idx *Index) Extract(from, to uint64) []byte {
+	if from > to || to > idx.length {
+		panic(fmt.Errorf("%w: range [%d, %d), length %d", bit.ErrOutOfRange, from, to, idx.length))
+	}
+
+	// start at the first sampled suffix at or after to, the sentinel suffix is row 0
+	p := (to + idx.sampleRate - 1) / idx.sampleRate * idx.sampleRate
+	var row uint64
+	if p <= idx.length {
+		row = idx.isaSamples[p/idx.sampleRate]
+	} else {
+		p = idx.length
+	}
+
+	// row holds suffix p, its BWT character is text[p-1]
+	text := make([]byte, to-from)
+	for ; p > from; p-- {
+		if p <= to {
+			text[p-1-from] = byte(idx.bwt.Access(row) - 1)
+		}
+		row = idx.lf(row)
+	}
+
+	return text
+}
+
+// Size in bits of the BWT, the counts and the samples
+func (idx *Index) Size() uint64 {
+	return idx.bwt.Size() + uint64(len(idx.c))*64 + idx.sampled.Size() + uint64(len(idx.saSamples)+len(idx.isaSamples))*64
+}
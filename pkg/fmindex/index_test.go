@@ -0,0 +1,145 @@
+package fmindex_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/fmindex"
+	"github.com/stretchr/testify/assert"
+)
+
+// Random text over the first alphabet lowercase letters
+func randomText(length, alphabet int) []byte {
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte('a' + rand.Intn(alphabet))
+	}
+	return text
+}
+
+func naiveLocate(text, pattern []byte) []uint64 {
+	positions := []uint64{}
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if bytes.Equal(text[i:i+len(pattern)], pattern) {
+			positions = append(positions, uint64(i))
+		}
+	}
+	return positions
+}
+
+func TestIndexVsNaive(t *testing.T) {
+	texts := map[string][]byte{
+		"empty":      {},
+		"single":     []byte("a"),
+		"banana":     []byte("banana"),
+		"repetitive": bytes.Repeat([]byte("abcab"), 500),
+		"binary":     randomText(5000, 2),
+		"dna":        randomText(5000, 4),
+		"all bytes":  []byte("\x00\xff\x00\x01\xff\xfe\x00"),
+	}
+
+	for name, text := range texts {
+		for _, rate := range []uint64{1, 3, fmindex.DefaultSampleRate} {
+			idx := fmindex.NewWithSampleRate(text, rate)
+			assert.Equal(t, uint64(len(text)), idx.Len())
+
+			patterns := [][]byte{[]byte("a"), []byte("ana"), []byte("zz"), {0x00}, {0xff, 0xfe}}
+			for range 30 {
+				if len(text) == 0 {
+					break
+				}
+				start := rand.Intn(len(text))
+				end := min(len(text), start+1+rand.Intn(6))
+				patterns = append(patterns, text[start:end])
+			}
+
+			for _, pattern := range patterns {
+				expected := naiveLocate(text, pattern)
+				assert.Equal(t, uint64(len(expected)), idx.Count(pattern), "%s: count %q", name, pattern)
+				assert.Equal(t, expected, idx.Locate(pattern), "%s: locate %q", name, pattern)
+			}
+
+			assert.Equal(t, text, idx.Extract(0, uint64(len(text))), name)
+			for range 30 {
+				from := uint64(rand.Intn(len(text) + 1))
+				to := from + uint64(rand.Intn(len(text)-int(from)+1))
+				assert.Equal(t, text[from:to], idx.Extract(from, to), "%s: extract [%d, %d)", name, from, to)
+			}
+		}
+	}
+}
+
+func TestIndexEncoding(t *testing.T) {
+	text := randomText(3000, 5)
+	idx := fmindex.NewWithSampleRate(text, 7)
+
+	var buf bytes.Buffer
+	written, err := idx.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+
+	encoded := bytes.Clone(buf.Bytes())
+
+	var decoded fmindex.Index
+	read, err := decoded.ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, written, read)
+
+	assert.Equal(t, text, decoded.Extract(0, decoded.Len()))
+	assert.Equal(t, naiveLocate(text, []byte("abc")), decoded.Locate([]byte("abc")))
+
+	corrupted := bytes.Clone(encoded)
+	corrupted[100] ^= 1
+	_, err = new(fmindex.Index).ReadFrom(bytes.NewReader(corrupted))
+	assert.ErrorIs(t, err, fmindex.ErrChecksumMismatch)
+
+	_, err = new(fmindex.Index).ReadFrom(bytes.NewReader(encoded[:len(encoded)-1]))
+	assert.ErrorIs(t, err, fmindex.ErrInvalidFormat)
+
+	_, err = new(fmindex.Index).ReadFrom(bytes.NewReader([]byte("IVEC")))
+	assert.ErrorIs(t, err, fmindex.ErrInvalidFormat)
+}
+
+func TestIndexDecodingCraftedHeader(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		length  uint64
+		rate    uint64
+		samples uint64
+	}{
+		{
+			desc:    "length wraps",
+			length:  math.MaxUint64,
+			rate:    1,
+			samples: 0,
+		},
+		{
+			desc:    "samples overflow",
+			length:  1 << 60,
+			rate:    1,
+			samples: 1<<60 + 1,
+		},
+		{
+			desc:    "large length without data",
+			length:  1 << 30,
+			rate:    1,
+			samples: 1<<30 + 1,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			header := make([]byte, 32)
+			copy(header, fmindex.EncodingMagic)
+			binary.LittleEndian.PutUint16(header[4:], fmindex.EncodingVersion)
+			binary.LittleEndian.PutUint64(header[8:], tC.length)
+			binary.LittleEndian.PutUint64(header[16:], tC.rate)
+			binary.LittleEndian.PutUint64(header[24:], tC.samples)
+
+			_, err := new(fmindex.Index).ReadFrom(bytes.NewReader(header))
+			assert.ErrorIs(t, err, fmindex.ErrInvalidFormat)
+		})
+	}
+}
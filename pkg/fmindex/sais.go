@@ -0,0 +1,140 @@
+package fmindex
+
+// Suffix array construction by induced sorting (SA-IS) of Nong, Zhang and Chan.
+// Suffixes are classified as S (smaller than the next suffix) or L (larger).
+// Sorting the leftmost S suffixes (LMS) is enough to induce the order of all
+// others. The LMS substrings are sorted by one induction, named, and if the
+// names are not unique the reduced string of names is sorted recursively.
+
+// Suffix array of s over the alphabet [0, alphabet). The last symbol of s
+// has to be a unique smallest sentinel.
+func sais(s []int32, alphabet int) []int32 {
+	n := len(s)
+	sa := make([]int32, n)
+	if n == 1 {
+		return sa
+	}
+
+	// true for S suffixes, the sentinel is S
+	stype := make([]bool, n)
+	stype[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		stype[i] = s[i] < s[i+1] || (s[i] == s[i+1] && stype[i+1])
+	}
+
+	isLMS := func(i int32) bool {
+		return i > 0 && stype[i] && !stype[i-1]
+	}
+
+	counts := make([]int32, alphabet)
+	for _, c := range s {
+		counts[c]++
+	}
+	buckets := make([]int32, alphabet)
+
+	bucketStarts := func() {
+		var sum int32
+		for c, count := range counts {
+			buckets[c] = sum
+			sum += count
+		}
+	}
+	bucketEnds := func() {
+		var sum int32
+		for c, count := range counts {
+			sum += count
+			buckets[c] = sum
+		}
+	}
+
+	// place the sorted LMS suffixes at their bucket ends and induce the rest
+	induce := func(lms []int32) {
+		for i := range sa {
+			sa[i] = -1
+		}
+
+		bucketEnds()
+		for i := len(lms) - 1; i >= 0; i-- {
+			j := lms[i]
+			buckets[s[j]]--
+			sa[buckets[s[j]]] = j
+		}
+
+		bucketStarts()
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; j >= 0 && !stype[j] {
+				sa[buckets[s[j]]] = j
+				buckets[s[j]]++
+			}
+		}
+
+		bucketEnds()
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; j >= 0 && stype[j] {
+				buckets[s[j]]--
+				sa[buckets[s[j]]] = j
+			}
+		}
+	}
+
+	var lms []int32
+	for i := int32(1); i < int32(n); i++ {
+		if isLMS(i) {
+			lms = append(lms, i)
+		}
+	}
+
+	// sort the LMS substrings
+	induce(lms)
+
+	// LMS substrings are equal if their symbols and types are equal up to the next LMS
+	equal := func(a, b int32) bool {
+		for i := int32(0); ; i++ {
+			aEnd, bEnd := isLMS(a+i), isLMS(b+i)
+			if i > 0 && aEnd && bEnd {
+				return true
+			}
+			if aEnd != bEnd || s[a+i] != s[b+i] || stype[a+i] != stype[b+i] {
+				return false
+			}
+		}
+	}
+
+	names := make([]int32, n)
+	var name int32
+	prev := int32(-1)
+	for _, p := range sa {
+		if !isLMS(p) {
+			continue
+		}
+		if prev >= 0 && !equal(prev, p) {
+			name++
+		}
+		names[p] = name
+		prev = p
+	}
+
+	// names in text order, the sentinel is the unique smallest name
+	reduced := make([]int32, len(lms))
+	for i, p := range lms {
+		reduced[i] = names[p]
+	}
+
+	var reducedSA []int32
+	if int(name)+1 < len(lms) {
+		reducedSA = sais(reduced, int(name)+1)
+	} else {
+		reducedSA = make([]int32, len(lms))
+		for i, c := range reduced {
+			reducedSA[c] = int32(i)
+		}
+	}
+
+	sortedLMS := make([]int32, len(lms))
+	for i, r := range reducedSA {
+		sortedLMS[i] = lms[r]
+	}
+
+	induce(sortedLMS)
+	return sa
+}
@@ -0,0 +1,179 @@
+package louds
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+)
+
+// Ordinal tree in level-order unary degree sequence (LOUDS) representation.
+// Nodes are numbered in breadth first order, the root is 0. After the
+// prefix 10 of a virtual super root, every node writes one one per child
+// followed by a zero, so a tree with n nodes takes 2n+1 bits.
+//
+// The description of node k starts after the (k+1)'th zero and node k itself
+// is represented by the (k+1)'th one, so navigation is a few Rank and Select
+// calls.
+
+var (
+	// The sequence is not a valid LOUDS sequence
+	ErrInvalidLOUDS = errors.New("invalid LOUDS sequence")
+	// The children are not numbered in breadth first order
+	ErrNotBFSOrder = errors.New("children not in BFS order")
+)
+
+type Tree struct {
+	louds *bit.InterleavedVector
+	nodes uint64
+}
+
+// Index a LOUDS sequence
+func NewTree(louds bit.Vector) (*Tree, error) {
+	if err := validate(louds); err != nil {
+		return nil, err
+	}
+
+	return &Tree{
+		louds: bit.NewInterleavedVector(louds),
+		nodes: louds.Bits() / 2,
+	}, nil
+}
+
+// Build the tree from the children of every node, where the nodes are
+// numbered in breadth first order: the children of node k are the next
+// unused numbers, starting with 1 for the first child of the root.
+func NewTreeFromChildren(children [][]int) (*Tree, error) {
+	louds, err := Encode(children)
+	if err != nil {
+		return nil, err
+	}
+	return NewTree(louds)
+}
+
+// LOUDS sequence of the tree given by the children of every node in BFS order
+func Encode(children [][]int) (bit.Vector, error) {
+	if len(children) == 0 {
+		return bit.Vector{}, fmt.Errorf("%w: a tree needs a root", ErrInvalidLOUDS)
+	}
+
+	var builder bit.Builder
+	builder.AppendBits(0b01, 2)
+
+	next := 1
+	for k, nodeChildren := range children {
+		for _, c := range nodeChildren {
+			if c != next {
+				return bit.Vector{}, fmt.Errorf("%w: child %d of node %d, expected %d", ErrNotBFSOrder, c, k, next)
+			}
+			next++
+		}
+		builder.AppendRun(true, uint64(len(nodeChildren)))
+		builder.AppendBit(false)
+	}
+
+	if next != len(children) {
+		return bit.Vector{}, fmt.Errorf("%w: %d of %d nodes reachable", ErrNotBFSOrder, next, len(children))
+	}
+
+	return builder.Vector(), nil
+}
+
+// Every node has to be referenced before it is described
+func validate(louds bit.Vector) error {
+	length := louds.Bits()
+	if length < 2 || !louds.Access(0) || louds.Access(1) {
+		return fmt.Errorf("%w: missing super root prefix", ErrInvalidLOUDS)
+	}
+
+	var ones, zeros uint64
+	for i := range length {
+		if louds.Access(i) {
+			ones++
+			continue
+		}
+
+		zeros++
+		// the last zero closes the last node
+		if zeros > ones && i != length-1 {
+			return fmt.Errorf("%w: node %d is described before it is referenced", ErrInvalidLOUDS, zeros-1)
+		}
+	}
+
+	if zeros != ones+1 {
+		return fmt.Errorf("%w: %d ones and %d zeros", ErrInvalidLOUDS, ones, zeros)
+	}
+	return nil
+}
+
+func (t *Tree) check(k uint64) {
+	if k >= t.nodes {
+		panic(fmt.Errorf("%w: node %d, %d nodes", bit.ErrOutOfRange, k, t.nodes))
+	}
+}
+
+// Number of nodes
+func (t *Tree) Nodes() uint64 {
+	return t.nodes
+}
+
+// Position of the first bit of the description of node k
+func (t *Tree) start(k uint64) uint64 {
+	return t.louds.Select(false, k+1) + 1
+}
+
+// Number of children of node k
+func (t *Tree) ChildCount(k uint64) uint64 {
+	t.check(k)
+	return t.louds.Select(false, k+2) - t.start(k)
+}
+
+// Whether node k has no children
+func (t *Tree) IsLeaf(k uint64) bool {
+	return t.ChildCount(k) == 0
+}
+
+// The i'th child of node k, i starts at 0, false if k has fewer children
+func (t *Tree) Child(k, i uint64) (uint64, bool) {
+	if i >= t.ChildCount(k) {
+		return 0, false
+	}
+	// the ones before the child's one are the nodes before it
+	return t.louds.Rank(true, t.start(k)+i), true
+}
+
+// First child of node k, false for a leaf
+func (t *Tree) FirstChild(k uint64) (uint64, bool) {
+	return t.Child(k, 0)
+}
+
+// Parent of node k, false for the root
+func (t *Tree) Parent(k uint64) (uint64, bool) {
+	t.check(k)
+	if k == 0 {
+		return 0, false
+	}
+
+	// the one of k lies in the description of its parent
+	return t.louds.Rank(false, t.louds.Select(true, k+1)) - 1, true
+}
+
+// Next sibling of node k, false for the last child
+func (t *Tree) NextSibling(k uint64) (uint64, bool) {
+	t.check(k)
+	if k == 0 {
+		return 0, false
+	}
+
+	// siblings are consecutive ones
+	next := t.louds.Select(true, k+1) + 1
+	if t.louds.Access(next) {
+		return k + 1, true
+	}
+	return 0, false
+}
+
+// Size in bits of the indexed sequence
+func (t *Tree) Size() uint64 {
+	return t.louds.Size()
+}
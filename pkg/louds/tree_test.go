@@ -0,0 +1,111 @@
+package louds_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/paulheg/kit_advanced_data_structures/pkg/louds"
+	"github.com/stretchr/testify/assert"
+)
+
+// Children of a random tree numbered in BFS order, every node has at most maxDegree children
+func randomBFSChildren(nodes, maxDegree int) [][]int {
+	children := make([][]int, nodes)
+	next := 1
+	for k := 0; k < nodes; k++ {
+		degree := rand.Intn(maxDegree + 1)
+		// the last open node has to take all remaining nodes
+		if k == next-1 {
+			degree = max(degree, 1)
+		}
+		for range degree {
+			if next == nodes {
+				break
+			}
+			children[k] = append(children[k], next)
+			next++
+		}
+	}
+	return children
+}
+
+func TestTreeVsNaive(t *testing.T) {
+	shapes := map[string][][]int{
+		"single node": {{}},
+		"path":        randomBFSChildren(1000, 1),
+		"binary":      randomBFSChildren(5000, 2),
+		"wide":        randomBFSChildren(5000, 50),
+	}
+
+	for name, children := range shapes {
+		t.Run(name, func(t *testing.T) {
+			tree, err := louds.NewTreeFromChildren(children)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(len(children)), tree.Nodes())
+
+			parents := make([]int, len(children))
+			parents[0] = -1
+			for k, nodeChildren := range children {
+				for _, c := range nodeChildren {
+					parents[c] = k
+				}
+			}
+
+			for k, nodeChildren := range children {
+				node := uint64(k)
+
+				assert.Equal(t, uint64(len(nodeChildren)), tree.ChildCount(node))
+				assert.Equal(t, len(nodeChildren) == 0, tree.IsLeaf(node))
+
+				parent, ok := tree.Parent(node)
+				assert.Equal(t, k != 0, ok)
+				if ok {
+					assert.Equal(t, uint64(parents[k]), parent, "parent of %d", k)
+				}
+
+				for i, c := range nodeChildren {
+					child, ok := tree.Child(node, uint64(i))
+					assert.True(t, ok)
+					assert.Equal(t, uint64(c), child)
+
+					sibling, ok := tree.NextSibling(child)
+					assert.Equal(t, i+1 < len(nodeChildren), ok)
+					if ok {
+						assert.Equal(t, uint64(nodeChildren[i+1]), sibling)
+					}
+				}
+
+				_, ok = tree.Child(node, uint64(len(nodeChildren)))
+				assert.False(t, ok)
+			}
+
+			_, ok := tree.NextSibling(0)
+			assert.False(t, ok)
+			assert.Panics(t, func() { tree.Parent(tree.Nodes()) })
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	vec, err := louds.Encode([][]int{{1, 2}, {3}, {}, {}})
+	assert.NoError(t, err)
+	assert.Equal(t, bit.NewVector("101101000").Subvectors(), vec.Subvectors())
+
+	_, err = louds.Encode([][]int{{2, 1}, {}, {}})
+	assert.ErrorIs(t, err, louds.ErrNotBFSOrder)
+	_, err = louds.Encode([][]int{{1}, {}, {}})
+	assert.ErrorIs(t, err, louds.ErrNotBFSOrder)
+	_, err = louds.Encode(nil)
+	assert.ErrorIs(t, err, louds.ErrInvalidLOUDS)
+}
+
+func TestInvalidLOUDS(t *testing.T) {
+	for _, sequence := range []string{"", "0", "1100", "10010", "1010", "101100"} {
+		_, err := louds.NewTree(bit.NewVector(sequence))
+		assert.ErrorIs(t, err, louds.ErrInvalidLOUDS, sequence)
+	}
+
+	_, err := louds.NewTree(bit.NewVector("100"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,47 @@
+package parentheses
+
+import "github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+
+// Balanced parentheses of the tree rooted in node 0, where children[v] lists
+// the children of v in order. The k'th node visited in preorder becomes the
+// node with preorder number k.
+func Encode(children [][]int) bit.Vector {
+	var builder bit.Builder
+	if len(children) == 0 {
+		return builder.Vector()
+	}
+
+	type frame struct {
+		node int
+		next int
+	}
+
+	stack := []frame{{node: 0}}
+	builder.AppendBit(true)
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next == len(children[top.node]) {
+			builder.AppendBit(false)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		child := children[top.node][top.next]
+		top.next++
+		builder.AppendBit(true)
+		stack = append(stack, frame{node: child})
+	}
+
+	return builder.Vector()
+}
+
+// Build the tree rooted in node 0 from the children of every node, see Encode
+func NewTreeFromChildren(children [][]int) *Tree {
+	t, err := NewTree(Encode(children))
+	if err != nil {
+		// a traversal always produces a balanced sequence
+		panic(err)
+	}
+	return t
+}
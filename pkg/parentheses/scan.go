@@ -0,0 +1,128 @@
+package parentheses
+
+import "github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+
+// Scans inside a block skip a whole byte of parentheses at once when the
+// byte cannot contain the searched excess, only the byte with the answer is
+// scanned bit by bit. Bit k of a byte is the parenthesis at position 8p+k.
+
+var (
+	// excess of the byte
+	byteExcess [256]int8
+	// minimal excess of a non-empty prefix of the byte
+	byteMinExcess [256]int8
+	// maximal excess of a suffix of the byte with at most 7 bits
+	byteMaxSuffix [256]int8
+)
+
+func init() {
+	for b := range 256 {
+		var e, minE int8 = 0, 8
+		for k := range 8 {
+			e += int8(b>>k&1)*2 - 1
+			minE = min(minE, e)
+		}
+		byteExcess[b] = e
+		byteMinExcess[b] = minE
+
+		var suffix, maxSuffix int8
+		for k := 7; k > 0; k-- {
+			suffix += int8(b>>k&1)*2 - 1
+			maxSuffix = max(maxSuffix, suffix)
+		}
+		byteMaxSuffix[b] = maxSuffix
+	}
+}
+
+// Subvector holding position i
+func (t *Tree) word(i uint64) uint64 {
+	_, sv := t.bp.GetSubvector(i)
+	return uint64(*sv)
+}
+
+// First j in [from, to) where the excess reaches target or less, cur is the
+// excess before from and afterwards the excess at j, or before to if not found
+func (t *Tree) scanForward(from, to uint64, cur *int64, target int64) (uint64, bool) {
+	for j := from; j < to; {
+		end := min(to, (j/bit.SubvectorBits+1)*bit.SubvectorBits)
+		w := t.word(j) >> (j % bit.SubvectorBits)
+
+		for j < end {
+			if j%8 == 0 && j+8 <= end {
+				b := uint8(w)
+				if *cur+int64(byteMinExcess[b]) > target {
+					*cur += int64(byteExcess[b])
+					w >>= 8
+					j += 8
+					continue
+				}
+			}
+
+			*cur += int64(w&1)*2 - 1
+			if *cur <= target {
+				return j, true
+			}
+			w >>= 1
+			j++
+		}
+	}
+
+	return to, false
+}
+
+// Last j in [from, to) whose excess is at most target, cur is the excess at
+// to-1 and afterwards the excess at j, or at from-1 if not found
+func (t *Tree) scanBackward(from, to uint64, cur *int64, target int64) (uint64, bool) {
+	for j := to; j > from; {
+		// j is exclusive, the next position to check is j-1
+		wordStart := (j - 1) / bit.SubvectorBits * bit.SubvectorBits
+		start := max(from, wordStart)
+		w := t.word(j-1) << (bit.SubvectorBits - (j - wordStart))
+
+		for j > start {
+			if j%8 == 0 && j-8 >= start {
+				b := uint8(w >> 56)
+				if *cur-int64(byteMaxSuffix[b]) > target {
+					*cur -= int64(byteExcess[b])
+					w <<= 8
+					j -= 8
+					continue
+				}
+			}
+
+			if *cur <= target {
+				return j - 1, true
+			}
+			*cur -= int64(w>>63)*2 - 1
+			w <<= 1
+			j--
+		}
+	}
+
+	return from, false
+}
+
+// Minimal excess in [from, to) into result, cur is the excess before from
+// and afterwards the excess at to-1
+func (t *Tree) scanMin(from, to uint64, cur, result *int64) {
+	for j := from; j < to; {
+		end := min(to, (j/bit.SubvectorBits+1)*bit.SubvectorBits)
+		w := t.word(j) >> (j % bit.SubvectorBits)
+
+		for j < end {
+			if j%8 == 0 && j+8 <= end {
+				b := uint8(w)
+				*result = min(*result, *cur+int64(byteMinExcess[b]))
+				*cur += int64(byteExcess[b])
+				w >>= 8
+				j += 8
+				continue
+			}
+
+			*cur += int64(w&1)*2 - 1
+			*result = min(*result, *cur)
+			w >>= 1
+			j++
+		}
+	}
+}
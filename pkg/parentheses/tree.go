@@ -0,0 +1,354 @@
+package parentheses
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+)
+
+// Ordinal tree in balanced parentheses (BP) representation.
+// A depth first traversal writes a one (open) when entering and a zero
+// (close) when leaving a node, so a tree with n nodes takes 2n bits.
+// A node is identified by the position of its open parenthesis, the root is 0.
+//
+// The excess E(i) is the number of opens minus the number of closes in
+// [0, i]. Navigation reduces to searching the next or previous position
+// with a given excess, which a range min-max tree answers: the sequence is
+// cut into blocks, and a complete binary tree over the blocks stores the
+// excess and the minimal prefix excess of every node.
+
+const blockBits = 256
+
+var (
+	// The sequence is not a balanced parentheses sequence
+	ErrUnbalanced = errors.New("unbalanced parentheses")
+	// The position does not hold the expected parenthesis
+	ErrWrongParenthesis = errors.New("wrong parenthesis")
+)
+
+// Minimal prefix excess of the padding leaves, never reached by a search
+const noMin = math.MaxInt64 / 2
+
+type Tree struct {
+	bp     *bit.InterleavedVector
+	length uint64
+
+	// number of leaves, a power of two, leaf k stores block k
+	leaves int
+	// heap layout: node 1 is the root, the children of node j are 2j and 2j+1
+	excess []int64
+	// minimal excess of a prefix of the node, relative to its start
+	minExcess []int64
+}
+
+// Index the balanced parentheses sequence of a single tree, a forest like
+// 1010 is rejected with ErrUnbalanced
+func NewTree(bp bit.Vector) (*Tree, error) {
+	t := &Tree{
+		bp:     bit.NewInterleavedVector(bp),
+		length: bp.Bits(),
+	}
+
+	blocks := int((t.length + blockBits - 1) / blockBits)
+	t.leaves = 1
+	for t.leaves < blocks {
+		t.leaves *= 2
+	}
+
+	t.excess = make([]int64, 2*t.leaves)
+	t.minExcess = make([]int64, 2*t.leaves)
+
+	for k := range t.leaves {
+		node := t.leaves + k
+		t.minExcess[node] = noMin
+
+		var e int64
+		if start := uint64(k) * blockBits; start < t.length {
+			t.scanMin(start, min(start+blockBits, t.length), &e, &t.minExcess[node])
+		}
+		t.excess[node] = e
+	}
+
+	for node := t.leaves - 1; node > 0; node-- {
+		l, r := 2*node, 2*node+1
+		t.excess[node] = t.excess[l] + t.excess[r]
+		t.minExcess[node] = min(t.minExcess[l], t.excess[l]+t.minExcess[r])
+	}
+
+	if t.length > 0 && (t.excess[1] != 0 || t.minExcess[1] < 0) {
+		return nil, fmt.Errorf("%w: excess %d, minimal excess %d", ErrUnbalanced, t.excess[1], t.minExcess[1])
+	}
+
+	// a single tree, the root only closes at the end and not before
+	if t.length >= 2 && t.minExcessIn(0, t.length-2) < 1 {
+		return nil, fmt.Errorf("%w: forest, the root closes before the end", ErrUnbalanced)
+	}
+
+	return t, nil
+}
+
+// Excess of [0, i], position -1 has excess 0
+func (t *Tree) excessAt(i int64) int64 {
+	return 2*int64(t.bp.Rank(true, uint64(i+1))) - (i + 1)
+}
+
+func (t *Tree) check(i uint64, open bool) {
+	if i >= t.length {
+		panic(fmt.Errorf("%w: position %d, length %d", bit.ErrOutOfRange, i, t.length))
+	}
+	if t.bp.Access(i) != open {
+		panic(fmt.Errorf("%w: position %d", ErrWrongParenthesis, i))
+	}
+}
+
+// Number of bits of the sequence
+func (t *Tree) Bits() uint64 {
+	return t.length
+}
+
+// Number of nodes
+func (t *Tree) Nodes() uint64 {
+	return t.length / 2
+}
+
+// First position after i with excess at most target, target < E(i)
+func (t *Tree) fwdSearch(i uint64, target int64) (uint64, bool) {
+	cur := t.excessAt(int64(i))
+
+	// rest of the block of i
+	block := i / blockBits
+	if j, ok := t.scanForward(i+1, min((block+1)*blockBits, t.length), &cur, target); ok {
+		return j, true
+	}
+
+	// climb up until a right sibling contains the target
+	node := t.leaves + int(block)
+	for {
+		if node == 1 {
+			return 0, false
+		}
+		if node%2 == 0 {
+			sibling := node + 1
+			if cur+t.minExcess[sibling] <= target {
+				node = sibling
+				break
+			}
+			cur += t.excess[sibling]
+		}
+		node /= 2
+	}
+
+	// descend into the leftmost leaf that contains it
+	for node < t.leaves {
+		l := 2 * node
+		if cur+t.minExcess[l] <= target {
+			node = l
+		} else {
+			cur += t.excess[l]
+			node = l + 1
+		}
+	}
+
+	start := uint64(node-t.leaves) * blockBits
+	return t.scanForward(start, min(start+blockBits, t.length), &cur, target)
+}
+
+// Last position before i with excess at most target, -1 if only the start
+// qualifies. target < E(i-1)
+func (t *Tree) bwdSearch(i uint64, target int64) (int64, bool) {
+	cur := t.excessAt(int64(i) - 1)
+
+	// rest of the block of i
+	block := i / blockBits
+	if j, ok := t.scanBackward(block*blockBits, i, &cur, target); ok {
+		return int64(j), true
+	}
+
+	// climb up until a left sibling contains the target, cur is the excess before node
+	node := t.leaves + int(block)
+	for {
+		if node == 1 {
+			if target >= 0 {
+				return -1, true
+			}
+			return 0, false
+		}
+		if node%2 == 1 {
+			sibling := node - 1
+			if cur-t.excess[sibling]+t.minExcess[sibling] <= target {
+				node = sibling
+				break
+			}
+			cur -= t.excess[sibling]
+		}
+		node /= 2
+	}
+
+	// descend into the rightmost leaf that contains it
+	for node < t.leaves {
+		r := 2*node + 1
+		if cur-t.excess[r]+t.minExcess[r] <= target {
+			node = r
+		} else {
+			cur -= t.excess[r]
+			node = r - 1
+		}
+	}
+
+	// a left sibling, so the block is complete
+	start := uint64(node-t.leaves) * blockBits
+	j, ok := t.scanBackward(start, start+blockBits, &cur, target)
+	return int64(j), ok
+}
+
+// Minimal excess in [i, j]
+func (t *Tree) minExcessIn(i, j uint64) int64 {
+	result := int64(noMin)
+
+	first, last := i/blockBits, j/blockBits
+	scanEnd := j
+	if first != last {
+		scanEnd = (first+1)*blockBits - 1
+	}
+
+	cur := t.excessAt(int64(i) - 1)
+	t.scanMin(i, scanEnd+1, &cur, &result)
+	if first == last {
+		return result
+	}
+
+	// full blocks in between, every node is relative to the excess before it
+	l, r := t.leaves+int(first)+1, t.leaves+int(last)
+	for l < r {
+		if l%2 == 1 {
+			result = min(result, t.nodeMin(l))
+			l++
+		}
+		if r%2 == 1 {
+			r--
+			result = min(result, t.nodeMin(r))
+		}
+		l /= 2
+		r /= 2
+	}
+
+	cur = t.excessAt(int64(last*blockBits) - 1)
+	t.scanMin(last*blockBits, j+1, &cur, &result)
+
+	return result
+}
+
+// Absolute minimal excess of a node
+func (t *Tree) nodeMin(node int) int64 {
+	leaf := node
+	for leaf < t.leaves {
+		leaf *= 2
+	}
+	return t.excessAt(int64(leaf-t.leaves)*blockBits-1) + t.minExcess[node]
+}
+
+// Position of the close parenthesis matching the open one at i
+func (t *Tree) FindClose(i uint64) uint64 {
+	t.check(i, true)
+	j, _ := t.fwdSearch(i, t.excessAt(int64(i))-1)
+	return j
+}
+
+// Position of the open parenthesis matching the close one at i
+func (t *Tree) FindOpen(i uint64) uint64 {
+	t.check(i, false)
+	j, _ := t.bwdSearch(i, t.excessAt(int64(i)))
+	return uint64(j + 1)
+}
+
+// Open parenthesis of the closest pair enclosing the open one at i,
+// false for the root
+func (t *Tree) Enclose(i uint64) (uint64, bool) {
+	t.check(i, true)
+	j, ok := t.bwdSearch(i, t.excessAt(int64(i))-2)
+	if !ok {
+		return 0, false
+	}
+	return uint64(j + 1), true
+}
+
+// Parent of node v, false for the root
+func (t *Tree) Parent(v uint64) (uint64, bool) {
+	return t.Enclose(v)
+}
+
+// First child of node v, false for a leaf
+func (t *Tree) FirstChild(v uint64) (uint64, bool) {
+	t.check(v, true)
+	if t.bp.Access(v + 1) {
+		return v + 1, true
+	}
+	return 0, false
+}
+
+// Next sibling of node v, false for the last child
+func (t *Tree) NextSibling(v uint64) (uint64, bool) {
+	next := t.FindClose(v) + 1
+	if next < t.length && t.bp.Access(next) {
+		return next, true
+	}
+	return 0, false
+}
+
+// Whether node v has no children
+func (t *Tree) IsLeaf(v uint64) bool {
+	_, ok := t.FirstChild(v)
+	return !ok
+}
+
+// Number of edges between node v and the root
+func (t *Tree) Depth(v uint64) uint64 {
+	t.check(v, true)
+	return uint64(t.excessAt(int64(v)) - 1)
+}
+
+// Number of nodes in the subtree of v, including v
+func (t *Tree) SubtreeSize(v uint64) uint64 {
+	return (t.FindClose(v) - v + 1) / 2
+}
+
+// Whether u is an ancestor of v or v itself
+func (t *Tree) IsAncestor(u, v uint64) bool {
+	t.check(v, true)
+	return u <= v && v < t.FindClose(u)
+}
+
+// Lowest common ancestor of the nodes u and v
+func (t *Tree) LCA(u, v uint64) uint64 {
+	if u > v {
+		u, v = v, u
+	}
+	if t.IsAncestor(u, v) {
+		return u
+	}
+
+	// the minimal excess between both is reached at the close of a child of
+	// the LCA, which is directly followed by the next child
+	m := t.minExcessIn(u, v)
+	p, _ := t.fwdSearch(u, m)
+	parent, _ := t.Parent(p + 1)
+	return parent
+}
+
+// Number of the node v in preorder, the root has number 0
+func (t *Tree) Preorder(v uint64) uint64 {
+	t.check(v, true)
+	return t.bp.Rank(true, v)
+}
+
+// Node with the given number in preorder
+func (t *Tree) Node(preorder uint64) uint64 {
+	return t.bp.Select(true, preorder+1)
+}
+
+// Size in bits of the sequence and the range min-max tree
+func (t *Tree) Size() uint64 {
+	return t.bp.Size() + uint64(len(t.excess)+len(t.minExcess))*64
+}
@@ -0,0 +1,198 @@
+package parentheses_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/paulheg/kit_advanced_data_structures/pkg/parentheses"
+	"github.com/stretchr/testify/assert"
+)
+
+// Random tree where the parent of node i is one of the window nodes before it
+func randomParents(nodes, window int) []int {
+	parents := make([]int, nodes)
+	parents[0] = -1
+	for i := 1; i < nodes; i++ {
+		parents[i] = i - 1 - rand.Intn(min(window, i))
+	}
+	return parents
+}
+
+func childrenOf(parents []int) [][]int {
+	children := make([][]int, len(parents))
+	for i, p := range parents {
+		if p >= 0 {
+			children[p] = append(children[p], i)
+		}
+	}
+	return children
+}
+
+// Position of the open parenthesis of every node and the nodes in preorder
+func naivePositions(children [][]int) (positions []uint64, preorder []int) {
+	positions = make([]uint64, len(children))
+
+	var pos uint64
+	var visit func(v int)
+	visit = func(v int) {
+		positions[v] = pos
+		preorder = append(preorder, v)
+		pos++
+		for _, c := range children[v] {
+			visit(c)
+		}
+		pos++
+	}
+	visit(0)
+
+	return positions, preorder
+}
+
+func naiveFindClose(bp bit.Vector, i uint64) uint64 {
+	excess := 0
+	for j := i; ; j++ {
+		if bp.Access(j) {
+			excess++
+		} else {
+			excess--
+		}
+		if excess == 0 {
+			return j
+		}
+	}
+}
+
+func TestTreeVsNaive(t *testing.T) {
+	shapes := map[string][]int{
+		"single node": {-1},
+		"path":        randomParents(3000, 1),
+		"deep":        randomParents(5000, 20),
+		"wide":        randomParents(5000, 5000),
+		"star":        append([]int{-1}, make([]int, 999)...),
+	}
+
+	for name, parents := range shapes {
+		t.Run(name, func(t *testing.T) {
+			children := childrenOf(parents)
+			positions, preorder := naivePositions(children)
+
+			bp := parentheses.Encode(children)
+			tree := parentheses.NewTreeFromChildren(children)
+			assert.Equal(t, uint64(2*len(parents)), tree.Bits())
+			assert.Equal(t, uint64(len(parents)), tree.Nodes())
+
+			depths := make([]uint64, len(parents))
+			sizes := make([]uint64, len(parents))
+			for i := len(parents) - 1; i >= 0; i-- {
+				sizes[i]++
+				if parents[i] >= 0 {
+					sizes[parents[i]] += sizes[i]
+				}
+			}
+
+			for k, x := range preorder {
+				v := positions[x]
+
+				if parents[x] >= 0 {
+					depths[x] = depths[parents[x]] + 1
+				}
+
+				assert.Equal(t, v, tree.Node(uint64(k)))
+				assert.Equal(t, uint64(k), tree.Preorder(v))
+				assert.Equal(t, depths[x], tree.Depth(v), "depth of %d", x)
+				assert.Equal(t, sizes[x], tree.SubtreeSize(v), "subtree of %d", x)
+
+				closing := tree.FindClose(v)
+				assert.Equal(t, naiveFindClose(bp, v), closing, "close of %d", v)
+				assert.Equal(t, v, tree.FindOpen(closing), "open of %d", closing)
+
+				parent, ok := tree.Parent(v)
+				assert.Equal(t, parents[x] >= 0, ok)
+				if ok {
+					assert.Equal(t, positions[parents[x]], parent, "parent of %d", x)
+				}
+
+				child, ok := tree.FirstChild(v)
+				assert.Equal(t, len(children[x]) > 0, ok)
+				assert.Equal(t, !ok, tree.IsLeaf(v))
+				if ok {
+					assert.Equal(t, positions[children[x][0]], child)
+				}
+
+				for j, c := range children[x] {
+					sibling, ok := tree.NextSibling(positions[c])
+					assert.Equal(t, j+1 < len(children[x]), ok)
+					if ok {
+						assert.Equal(t, positions[children[x][j+1]], sibling)
+					}
+				}
+			}
+
+			for range 500 {
+				a, b := rand.Intn(len(parents)), rand.Intn(len(parents))
+
+				// walk up from the deeper node until both meet
+				x, y := a, b
+				for x != y {
+					if depths[x] >= depths[y] {
+						x = parents[x]
+					} else {
+						y = parents[y]
+					}
+				}
+
+				assert.Equal(t, positions[x], tree.LCA(positions[a], positions[b]), fmt.Sprintf("lca of %d and %d", a, b))
+			}
+		})
+	}
+}
+
+func TestTreeErrors(t *testing.T) {
+	for _, bp := range []string{"110", "0110", "1001", "0", "1010", "110010"} {
+		_, err := parentheses.NewTree(bit.NewVector(bp))
+		assert.ErrorIs(t, err, parentheses.ErrUnbalanced, bp)
+	}
+
+	tree, err := parentheses.NewTree(bit.NewVector("110100"))
+	assert.NoError(t, err)
+
+	assert.PanicsWithError(t, "wrong parenthesis: position 2", func() { tree.FindClose(2) })
+	assert.PanicsWithError(t, "wrong parenthesis: position 1", func() { tree.FindOpen(1) })
+	assert.Panics(t, func() { tree.Depth(6) })
+}
+
+func BenchmarkTree(b *testing.B) {
+	// deep and wide subtrees, so most searches leave their block
+	children := childrenOf(randomParents(1<<20, 1000))
+	tree := parentheses.NewTreeFromChildren(children)
+	positions, _ := naivePositions(children)
+
+	nodes := make([]uint64, 1024)
+	for k := range nodes {
+		nodes[k] = positions[rand.Intn(len(positions))]
+	}
+
+	b.Run("FindClose", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.FindClose(nodes[i%len(nodes)])
+		}
+	})
+	b.Run("Parent", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.Parent(nodes[i%len(nodes)])
+		}
+	})
+	b.Run("LCA", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.LCA(nodes[i%len(nodes)], nodes[(i+1)%len(nodes)])
+		}
+	})
+	b.Run("NewTree", func(b *testing.B) {
+		bp := parentheses.Encode(children)
+		for i := 0; i < b.N; i++ {
+			parentheses.NewTree(bp)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package wavelet
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+)
+
+// Wavelet matrix by Claude, Navarro and Ordóñez.
+// Level l holds bit l (from the most significant one) of every symbol. Unlike
+// the tree, each level is stably partitioned as a whole: all symbols with a
+// zero bit go first, then all with a one bit. A position moves to
+// rank0(i) or zeros[l] + rank1(i) on the next level, which only needs one
+// vector and one count per level, independent of the alphabet size.
+type Matrix struct {
+	levels []*bit.InterleavedVector
+	// number of zeros of every level
+	zeros  []uint64
+	length uint64
+}
+
+// Build a matrix with interleaved vectors as levels
+func NewMatrix(sequence []uint32) *Matrix {
+	depth := symbolBits(sequence)
+
+	m := &Matrix{
+		levels: make([]*bit.InterleavedVector, depth),
+		zeros:  make([]uint64, depth),
+		length: uint64(len(sequence)),
+	}
+
+	current := slices.Clone(sequence)
+	next := make([]uint32, len(sequence))
+
+	for l := range depth {
+		shift := depth - 1 - l
+
+		vec := bit.MakeVector(m.length)
+		for i, symbol := range current {
+			if symbol>>shift&1 == 1 {
+				vec.Set(uint64(i))
+			}
+		}
+		m.levels[l] = bit.NewInterleavedVector(vec)
+		m.zeros[l] = m.length - vec.Ones()
+
+		// stable partition: zeros first, then ones
+		z, o := 0, int(m.zeros[l])
+		for _, symbol := range current {
+			if symbol>>shift&1 == 1 {
+				next[o] = symbol
+				o++
+			} else {
+				next[z] = symbol
+				z++
+			}
+		}
+		current, next = next, current
+	}
+
+	return m
+}
+
+// Length of the sequence
+func (m *Matrix) Len() uint64 {
+	return m.length
+}
+
+// Bit of symbol on level l
+func (m *Matrix) symbolBit(symbol uint32, l int) bool {
+	return symbol>>(len(m.levels)-1-l)&1 == 1
+}
+
+// Position i of level l on level l+1, when following b
+func (m *Matrix) follow(l int, i uint64, b bool) uint64 {
+	if b {
+		return m.zeros[l] + m.levels[l].Rank(true, i)
+	}
+	return m.levels[l].Rank(false, i)
+}
+
+func (m *Matrix) checkRange(start, end uint64) {
+	if start > end || end > m.length {
+		panic(fmt.Errorf("%w: range [%d, %d), length %d", bit.ErrOutOfRange, start, end, m.length))
+	}
+}
+
+// Symbol at position i
+func (m *Matrix) Access(i uint64) uint32 {
+	if i >= m.length {
+		panic(fmt.Errorf("%w: position %d, length %d", bit.ErrOutOfRange, i, m.length))
+	}
+
+	var symbol uint32
+	for l, level := range m.levels {
+		b := level.Access(i)
+		if b {
+			symbol |= 1 << (len(m.levels) - 1 - l)
+		}
+		i = m.follow(l, i, b)
+	}
+
+	return symbol
+}
+
+// Number of occurrences of symbol before position i
+func (m *Matrix) Rank(symbol uint32, i uint64) uint64 {
+	m.checkRange(0, i)
+
+	if bits.Len32(symbol) > len(m.levels) {
+		return 0
+	}
+
+	start, end := uint64(0), i
+	for l := range m.levels {
+		b := m.symbolBit(symbol, l)
+		start, end = m.follow(l, start, b), m.follow(l, end, b)
+	}
+
+	return end - start
+}
+
+// Position of the n'th occurrence of symbol, n starts at 1
+func (m *Matrix) Select(symbol uint32, n uint64) uint64 {
+	if n == 0 {
+		panic(fmt.Errorf("%w: occurrences start at 1", bit.ErrOutOfRange))
+	}
+
+	if bits.Len32(symbol) > len(m.levels) {
+		panic(fmt.Errorf("%w: symbol %d does not occur", bit.ErrNotEnoughOccurrences, symbol))
+	}
+
+	// all occurrences end up next to each other on the last level
+	start, end := uint64(0), m.length
+	for l := range m.levels {
+		b := m.symbolBit(symbol, l)
+		start, end = m.follow(l, start, b), m.follow(l, end, b)
+	}
+
+	if end-start < n {
+		panic(fmt.Errorf("%w: %d. %d requested, only %d available", bit.ErrNotEnoughOccurrences, n, symbol, end-start))
+	}
+
+	// invert follow level by level
+	i := start + n - 1
+	for l := len(m.levels) - 1; l >= 0; l-- {
+		if m.symbolBit(symbol, l) {
+			i = m.levels[l].Select(true, i-m.zeros[l]+1)
+		} else {
+			i = m.levels[l].Select(false, i+1)
+		}
+	}
+
+	return i
+}
+
+// The k'th smallest symbol in [start, end), k starts at 0
+func (m *Matrix) Quantile(start, end, k uint64) uint32 {
+	m.checkRange(start, end)
+	if k >= end-start {
+		panic(fmt.Errorf("%w: %d. smallest of %d symbols", bit.ErrOutOfRange, k, end-start))
+	}
+
+	var symbol uint32
+	for l, level := range m.levels {
+		zeros := level.Rank(false, end) - level.Rank(false, start)
+
+		b := k >= zeros
+		if b {
+			k -= zeros
+			symbol |= 1 << (len(m.levels) - 1 - l)
+		}
+		start, end = m.follow(l, start, b), m.follow(l, end, b)
+	}
+
+	return symbol
+}
+
+// Number of positions in [start, end) with a symbol in [low, high)
+func (m *Matrix) RangeFreq(start, end uint64, low, high uint32) uint64 {
+	m.checkRange(start, end)
+	if low >= high {
+		return 0
+	}
+
+	return m.rangeLess(start, end, uint64(high)) - m.rangeLess(start, end, uint64(low))
+}
+
+// Number of positions in [start, end) with a symbol smaller than x
+func (m *Matrix) rangeLess(start, end uint64, x uint64) uint64 {
+	if bits.Len64(x) > len(m.levels) {
+		return end - start
+	}
+
+	var less uint64
+	for l, level := range m.levels {
+		b := x>>(len(m.levels)-1-l)&1 == 1
+		if b {
+			// everything going to the zero side is smaller
+			less += level.Rank(false, end) - level.Rank(false, start)
+		}
+		start, end = m.follow(l, start, b), m.follow(l, end, b)
+	}
+
+	return less
+}
+
+// Size in bits of all levels and zero counts
+func (m *Matrix) Size() uint64 {
+	size := uint64(len(m.zeros)) * 64
+	for _, level := range m.levels {
+		size += level.Size()
+	}
+	return size
+}
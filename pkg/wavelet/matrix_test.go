@@ -0,0 +1,78 @@
+package wavelet_test
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/wavelet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixVsNaive(t *testing.T) {
+	for _, sigma := range []uint32{1, 3, 256, 1 << 20} {
+		t.Run(fmt.Sprintf("sigma %d", sigma), func(t *testing.T) {
+			sequence := randomSequence(2000, sigma)
+			matrix := wavelet.NewMatrix(sequence)
+
+			for i := range sequence {
+				assert.Equal(t, sequence[i], matrix.Access(uint64(i)))
+			}
+
+			for k := 0; k < 300; k++ {
+				symbol := sequence[rand.Intn(len(sequence))]
+				i := uint64(rand.Intn(len(sequence) + 1))
+				assert.Equal(t, naiveRank(sequence, symbol, i), matrix.Rank(symbol, i))
+
+				count := naiveRank(sequence, symbol, uint64(len(sequence)))
+				n := uint64(rand.Int63n(int64(count))) + 1
+				expected, _ := naiveSelect(sequence, symbol, n)
+				assert.Equal(t, expected, matrix.Select(symbol, n))
+			}
+		})
+	}
+}
+
+func TestMatrixQuantileAndRangeFreq(t *testing.T) {
+	sequence := randomSequence(1000, 50)
+	matrix := wavelet.NewMatrix(sequence)
+
+	for k := 0; k < 300; k++ {
+		start := uint64(rand.Intn(len(sequence)))
+		end := start + 1 + uint64(rand.Intn(len(sequence)-int(start)))
+
+		sorted := slices.Clone(sequence[start:end])
+		slices.Sort(sorted)
+
+		q := uint64(rand.Intn(len(sorted)))
+		assert.Equal(t, sorted[q], matrix.Quantile(start, end, q))
+
+		low := rand.Uint32() % 60
+		high := low + rand.Uint32()%20
+		var expected uint64
+		for _, s := range sequence[start:end] {
+			if s >= low && s < high {
+				expected++
+			}
+		}
+		assert.Equal(t, expected, matrix.RangeFreq(start, end, low, high), "[%d, %d)", low, high)
+	}
+}
+
+func TestMatrixErrors(t *testing.T) {
+	matrix := wavelet.NewMatrix([]uint32{3, 1, 4, 1, 5})
+
+	assert.Equal(t, uint64(5), matrix.RangeFreq(0, 5, 0, 1<<31))
+	assert.Equal(t, uint64(0), matrix.Rank(2, 5))
+
+	assert.Panics(t, func() {
+		matrix.Select(1, 3)
+	})
+	assert.Panics(t, func() {
+		matrix.Quantile(2, 2, 0)
+	})
+	assert.Panics(t, func() {
+		matrix.Rank(1, 6)
+	})
+}
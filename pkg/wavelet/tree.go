@@ -0,0 +1,201 @@
+package wavelet
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+)
+
+// Builds the rank select structure of one level
+type VectorBuilder func(vec bit.Vector) bit.RankSelectVector
+
+// Interleaved vectors with precomputed pre sums
+var DefaultVectorBuilder VectorBuilder = func(vec bit.Vector) bit.RankSelectVector {
+	return bit.NewInterleavedVector(vec)
+}
+
+// Balanced wavelet tree over a sequence of symbols.
+// The tree is stored level by level: level l holds bit l (from the most
+// significant one) of every symbol, where the symbols are stably ordered by
+// their first l bits. A node therefore is an interval of its level and its
+// children split the same interval of the next level into zeros and ones,
+// so no pointers are needed.
+type Tree struct {
+	levels []bit.RankSelectVector
+	length uint64
+}
+
+// Build a tree with interleaved vectors as levels
+func NewTree(sequence []uint32) *Tree {
+	return NewTreeWithBuilder(sequence, DefaultVectorBuilder)
+}
+
+// Build a tree with levels created by builder
+func NewTreeWithBuilder(sequence []uint32, builder VectorBuilder) *Tree {
+	depth := symbolBits(sequence)
+
+	t := &Tree{
+		levels: make([]bit.RankSelectVector, depth),
+		length: uint64(len(sequence)),
+	}
+
+	current := slices.Clone(sequence)
+	next := make([]uint32, len(sequence))
+	for l := range depth {
+		shift := depth - 1 - l
+
+		vec := bit.MakeVector(t.length)
+		for i, symbol := range current {
+			if symbol>>shift&1 == 1 {
+				vec.Set(uint64(i))
+			}
+		}
+		t.levels[l] = builder(vec)
+
+		// current is ordered by the first l bits, so the nodes are runs of
+		// equal prefixes. Partitioning every node stably by bit l orders it
+		// by the first l+1 bits.
+		for start := 0; start < len(current); {
+			prefix := current[start] >> (shift + 1)
+			end := start
+			for end < len(current) && current[end]>>(shift+1) == prefix {
+				end++
+			}
+
+			k := start
+			for b := range uint32(2) {
+				for _, symbol := range current[start:end] {
+					if symbol>>shift&1 == b {
+						next[k] = symbol
+						k++
+					}
+				}
+			}
+
+			start = end
+		}
+		current, next = next, current
+	}
+
+	return t
+}
+
+// Number of bits per symbol, at least one
+func symbolBits(sequence []uint32) int {
+	var maxSymbol uint32
+	for _, symbol := range sequence {
+		maxSymbol = max(maxSymbol, symbol)
+	}
+	return max(bits.Len32(maxSymbol), 1)
+}
+
+// Length of the sequence
+func (t *Tree) Len() uint64 {
+	return t.length
+}
+
+// Number of bits per symbol
+func (t *Tree) Depth() int {
+	return len(t.levels)
+}
+
+// Interval [start, end) of the child of node [start, end) on level l
+func (t *Tree) child(l int, start, end uint64, b bool) (uint64, uint64) {
+	level := t.levels[l]
+	zeros := level.Rank(false, end) - level.Rank(false, start)
+
+	if b {
+		return start + zeros, end
+	}
+	return start, start + zeros
+}
+
+// Symbol at position i
+func (t *Tree) Access(i uint64) uint32 {
+	if i >= t.length {
+		panic(fmt.Errorf("%w: position %d, length %d", bit.ErrOutOfRange, i, t.length))
+	}
+
+	var symbol uint32
+	start, end := uint64(0), t.length
+
+	for l, level := range t.levels {
+		b := level.Access(start + i)
+		if b {
+			symbol |= 1 << (len(t.levels) - 1 - l)
+		}
+
+		// position inside the child node
+		i = level.Rank(b, start+i) - level.Rank(b, start)
+		start, end = t.child(l, start, end, b)
+	}
+
+	return symbol
+}
+
+// Number of occurrences of symbol before position i
+func (t *Tree) Rank(symbol uint32, i uint64) uint64 {
+	if i > t.length {
+		panic(fmt.Errorf("%w: position %d, length %d", bit.ErrOutOfRange, i, t.length))
+	}
+
+	if bits.Len32(symbol) > len(t.levels) {
+		return 0
+	}
+
+	start, end := uint64(0), t.length
+
+	for l, level := range t.levels {
+		b := symbol>>(len(t.levels)-1-l)&1 == 1
+
+		i = level.Rank(b, start+i) - level.Rank(b, start)
+		start, end = t.child(l, start, end, b)
+	}
+
+	return i
+}
+
+// Position of the n'th occurrence of symbol, n starts at 1
+func (t *Tree) Select(symbol uint32, n uint64) uint64 {
+	if n == 0 {
+		panic(fmt.Errorf("%w: occurrences start at 1", bit.ErrOutOfRange))
+	}
+
+	if bits.Len32(symbol) > len(t.levels) {
+		panic(fmt.Errorf("%w: symbol %d does not occur", bit.ErrNotEnoughOccurrences, symbol))
+	}
+
+	// walk down to the leaf and remember the start of every node on the way
+	starts := make([]uint64, len(t.levels))
+	start, end := uint64(0), t.length
+	for l := range t.levels {
+		starts[l] = start
+		start, end = t.child(l, start, end, symbol>>(len(t.levels)-1-l)&1 == 1)
+	}
+
+	if end-start < n {
+		panic(fmt.Errorf("%w: %d. %d requested, only %d available", bit.ErrNotEnoughOccurrences, n, symbol, end-start))
+	}
+
+	// walk up again, mapping the position inside the node to its parent
+	i := n - 1
+	for l := len(t.levels) - 1; l >= 0; l-- {
+		level := t.levels[l]
+		b := symbol>>(len(t.levels)-1-l)&1 == 1
+
+		i = level.Select(b, level.Rank(b, starts[l])+i+1) - starts[l]
+	}
+
+	return i
+}
+
+// Size in bits of all levels
+func (t *Tree) Size() uint64 {
+	var size uint64
+	for _, level := range t.levels {
+		size += level.Size()
+	}
+	return size
+}
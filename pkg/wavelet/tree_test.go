@@ -0,0 +1,96 @@
+package wavelet_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/paulheg/kit_advanced_data_structures/pkg/bit"
+	"github.com/paulheg/kit_advanced_data_structures/pkg/wavelet"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomSequence(length int, sigma uint32) []uint32 {
+	sequence := make([]uint32, length)
+	for i := range sequence {
+		sequence[i] = rand.Uint32() % sigma
+	}
+	return sequence
+}
+
+// Occurrences of symbol before i
+func naiveRank(sequence []uint32, symbol uint32, i uint64) uint64 {
+	var rank uint64
+	for _, s := range sequence[:i] {
+		if s == symbol {
+			rank++
+		}
+	}
+	return rank
+}
+
+// Position of the n'th symbol
+func naiveSelect(sequence []uint32, symbol uint32, n uint64) (uint64, bool) {
+	for i, s := range sequence {
+		if s == symbol {
+			n--
+			if n == 0 {
+				return uint64(i), true
+			}
+		}
+	}
+	return 0, false
+}
+
+var treeBuilders = map[string]wavelet.VectorBuilder{
+	"interleaved": wavelet.DefaultVectorBuilder,
+	"rank9": func(vec bit.Vector) bit.RankSelectVector {
+		return bit.NewRank9Vector(vec)
+	},
+}
+
+func TestTreeVsNaive(t *testing.T) {
+	for builderName, builder := range treeBuilders {
+		for _, sigma := range []uint32{1, 2, 5, 256, 100_000} {
+			t.Run(fmt.Sprintf("%s: sigma %d", builderName, sigma), func(t *testing.T) {
+				sequence := randomSequence(3000, sigma)
+				tree := wavelet.NewTreeWithBuilder(sequence, builder)
+
+				for i := range sequence {
+					assert.Equal(t, sequence[i], tree.Access(uint64(i)))
+				}
+
+				for k := 0; k < 300; k++ {
+					symbol := sequence[rand.Intn(len(sequence))]
+					i := uint64(rand.Intn(len(sequence) + 1))
+					assert.Equal(t, naiveRank(sequence, symbol, i), tree.Rank(symbol, i))
+
+					count := naiveRank(sequence, symbol, uint64(len(sequence)))
+					n := uint64(rand.Int63n(int64(count))) + 1
+					expected, _ := naiveSelect(sequence, symbol, n)
+					assert.Equal(t, expected, tree.Select(symbol, n))
+				}
+			})
+		}
+	}
+}
+
+func TestTreeMissingSymbols(t *testing.T) {
+	tree := wavelet.NewTree([]uint32{3, 1, 4, 1, 5})
+
+	assert.Equal(t, 3, tree.Depth())
+	assert.Equal(t, uint64(0), tree.Rank(2, 5))
+	assert.Equal(t, uint64(0), tree.Rank(1000, 5))
+	assert.Equal(t, uint64(2), tree.Rank(1, 5))
+	assert.Equal(t, uint64(3), tree.Select(1, 2))
+
+	assert.Panics(t, func() {
+		tree.Select(2, 1)
+	})
+	assert.Panics(t, func() {
+		tree.Select(1, 3)
+	})
+	assert.Panics(t, func() {
+		tree.Access(5)
+	})
+}